@@ -0,0 +1,107 @@
+package dct
+
+import (
+	"runtime"
+	"sync"
+)
+
+// BlockDCT2D replaces each n x n block of a flattened width x height plane with
+// its 2D DCT-II in place, JPEG style, with the blocks shared out over
+// GOMAXPROCS goroutines. width and height must be multiples of n, see
+// PadBlocks. n = 8 uses the AAN kernel and powers of 2 the static tables.
+func BlockDCT2D(plane []float64, width, height, n int) error {
+	if width <= 0 || height <= 0 || len(plane) != width*height {
+		return ErrInvalidInput
+	}
+	return block_2d(Matrix{Data: plane, Rows: height, Cols: width, Stride: width}, n, block_dct_kernel(n))
+}
+
+// BlockIDCT2D is the inverse of BlockDCT2D.
+func BlockIDCT2D(plane []float64, width, height, n int) error {
+	if width <= 0 || height <= 0 || len(plane) != width*height {
+		return ErrInvalidInput
+	}
+	return block_2d(Matrix{Data: plane, Rows: height, Cols: width, Stride: width}, n, block_idct_kernel(n))
+}
+
+func block_dct_kernel(n int) func() func([]float64) {
+	return func() func([]float64) {
+		if n == 8 {
+			return fct8_2d[float64]
+		}
+		kernel := dct_1d_kernel[float64](n)
+		return func(block []float64) { separable_2d(block, n, n, kernel, kernel) }
+	}
+}
+
+func block_idct_kernel(n int) func() func([]float64) {
+	return func() func([]float64) {
+		kernel := idct_1d_kernel[float64](n)
+		return func(block []float64) { separable_2d(block, n, n, kernel, kernel) }
+	}
+}
+
+// PadBlocks returns plane extended to width and height rounded up to multiples
+// of n by repeating its last column and row, as JPEG encoders do, together
+// with the new width and height. plane is returned as is if no padding is
+// needed. Errors are those of BlockDCT2D.
+func PadBlocks(plane []float64, width, height, n int) ([]float64, int, int, error) {
+	if width <= 0 || height <= 0 || len(plane) != width*height {
+		return nil, 0, 0, ErrInvalidInput
+	}
+	if n <= 0 {
+		return nil, 0, 0, ErrInvalidSize
+	}
+
+	pw := (width + n - 1) / n * n
+	ph := (height + n - 1) / n * n
+	if pw == width && ph == height {
+		return plane, width, height, nil
+	}
+
+	padded := make([]float64, pw*ph)
+	for y := 0; y < ph; y++ {
+		src := plane[min(y, height-1)*width:][:width]
+		dst := padded[y*pw:][:pw]
+		copy(dst, src)
+		for x := width; x < pw; x++ {
+			dst[x] = src[width-1]
+		}
+	}
+	return padded, pw, ph, nil
+}
+
+// block_2d runs the transform from new_kernel over every block of m, each
+// worker taking every workers-th row of blocks with a kernel and block of its
+// own. m must be valid.
+func block_2d(m Matrix, n int, new_kernel func() func([]float64)) error {
+	if n <= 0 || m.Cols%n != 0 || m.Rows%n != 0 {
+		return ErrInvalidSize
+	}
+
+	block_rows := m.Rows / n
+	workers := min(runtime.GOMAXPROCS(0), block_rows)
+
+	wg := new(sync.WaitGroup)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			kernel := new_kernel()
+			block := make([]float64, n*n)
+			for by := w; by < block_rows; by += workers {
+				for bx := 0; bx < m.Cols; bx += n {
+					for y := 0; y < n; y++ {
+						copy(block[y*n:(y+1)*n], m.Data[(by*n+y)*m.Stride+bx:])
+					}
+					kernel(block)
+					for y := 0; y < n; y++ {
+						copy(m.Data[(by*n+y)*m.Stride+bx:], block[y*n:(y+1)*n])
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	return nil
+}
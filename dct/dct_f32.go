@@ -0,0 +1,167 @@
+package dct
+
+import (
+	"math"
+	"slices"
+)
+
+// DCT_1D_F32 is DCT_1D for float32 input.
+func DCT_1D_F32(input []float32, sz int) []float32 {
+	result := slices.Clone(input)
+
+	dct_1d_kernel[float32](sz)(result)
+	return result
+}
+
+// IDCT_1D_F32 is IDCT_1D for float32 input.
+func IDCT_1D_F32(input []float32, sz int) []float32 {
+	result := slices.Clone(input)
+
+	idct_1d_kernel[float32](sz)(result)
+	return result
+}
+
+// DCT_2D_F32 is DCT_2D for float32 input.
+func DCT_2D_F32(input []float32, sz int) []float32 {
+	if sz == 0 {
+		sz = int(math.Sqrt(float64(len(input))))
+	}
+
+	result := slices.Clone(input)
+	if sz == 8 {
+		fct8_2d(result) // Arai, Agui, Nakajima
+		return result
+	}
+
+	if (sz&(sz-1)) == 0 || sz >= fft_threshold { // Lee with static tables up to 256, or an FFT
+		kernel := dct_1d_kernel[float32](sz)
+		separable_2d(result, sz, sz, kernel, kernel)
+		return result
+	}
+
+	dct_2d(result, sz)
+	return result
+}
+
+// IDCT_2D_F32 is IDCT_2D for float32 input.
+func IDCT_2D_F32(input []float32, sz int) []float32 {
+	if sz == 0 {
+		sz = int(math.Sqrt(float64(len(input))))
+	}
+
+	result := slices.Clone(input)
+	if (sz&(sz-1)) == 0 || sz >= fft_threshold {
+		kernel := idct_1d_kernel[float32](sz)
+		separable_2d(result, sz, sz, kernel, kernel)
+		return result
+	}
+
+	idct_2d(result, sz)
+	return result
+}
+
+// MatrixF32 is Matrix for float32 data.
+type MatrixF32 struct {
+	Data       []float32
+	Rows, Cols int
+	Stride     int
+}
+
+// NewMatrixF32 returns a zeroed rows x cols MatrixF32.
+func NewMatrixF32(rows, cols int) MatrixF32 {
+	return MatrixF32{Data: make([]float32, rows*cols), Rows: rows, Cols: cols, Stride: cols}
+}
+
+// Row returns row i, sharing its storage with m.
+func (m MatrixF32) Row(i int) []float32 {
+	return m.Data[i*m.Stride : i*m.Stride+m.Cols : i*m.Stride+m.Cols]
+}
+
+// Sub returns the rows x cols window of m with its top left corner at row i,
+// column j, sharing its storage with m.
+func (m MatrixF32) Sub(i, j, rows, cols int) MatrixF32 {
+	if i < 0 || j < 0 || rows <= 0 || cols <= 0 || i+rows > m.Rows || j+cols > m.Cols {
+		panic("dct: MatrixF32.Sub out of range")
+	}
+	return MatrixF32{Data: m.Data[i*m.Stride+j:], Rows: rows, Cols: cols, Stride: m.Stride}
+}
+
+// DCTMatrixF32 is DCTMatrix for float32 data.
+func DCTMatrixF32(m MatrixF32) error {
+	if err := check_strided(len(m.Data), m.Rows, m.Cols, m.Stride); err != nil {
+		return err
+	}
+
+	if m.Rows == 1 {
+		dct_1d_kernel[float32](m.Cols)(m.Row(0))
+		return nil
+	}
+
+	if m.Rows == 8 && m.Cols == 8 && m.Stride == 8 {
+		fct8_2d(m.Data[:64]) // Arai, Agui, Nakajima
+		return nil
+	}
+
+	separable_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, dct_1d_kernel[float32](m.Cols), dct_1d_kernel[float32](m.Rows))
+	return nil
+}
+
+// IDCTMatrixF32 is the inverse of DCTMatrixF32.
+func IDCTMatrixF32(m MatrixF32) error {
+	if err := check_strided(len(m.Data), m.Rows, m.Cols, m.Stride); err != nil {
+		return err
+	}
+
+	if m.Rows == 1 {
+		idct_1d_kernel[float32](m.Cols)(m.Row(0))
+		return nil
+	}
+
+	separable_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, idct_1d_kernel[float32](m.Cols), idct_1d_kernel[float32](m.Rows))
+	return nil
+}
+
+// PartialDCT2DF32 returns the kxk coefficients of the 2D DCT-II of the nxn
+// src from frequency from on, flattened, scaled by sqrt(2/n) in each
+// dimension, DC included. It multiplies by the kxn DCT matrix D as
+// D src D^T, with float32 products and sums in the order of PDQ's reference,
+// D src first, so its results match it bit for bit. src is not modified.
+func PartialDCT2DF32(src []float32, n, from, k int) ([]float32, error) {
+	if n <= 0 || len(src) != n*n {
+		return nil, ErrInvalidInput
+	}
+	if from < 0 || k <= 0 || from+k > n {
+		return nil, ErrInvalidSize
+	}
+
+	d := make([]float32, k*n)
+	scale := float64(float32(math.Sqrt(2 / float64(n))))
+	for i := 0; i < k; i++ {
+		for j := 0; j < n; j++ {
+			d[i*n+j] = float32(scale * math.Cos(math.Pi/2/float64(n)*float64(from+i)*float64(2*j+1)))
+		}
+	}
+
+	temp := make([]float32, k*n)
+	for i := 0; i < k; i++ {
+		for j := 0; j < n; j++ {
+			var sum float32
+			for l := 0; l < n; l++ {
+				sum += float32(d[i*n+l] * src[l*n+j])
+			}
+			temp[i*n+j] = sum
+		}
+	}
+
+	result := make([]float32, k*k)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			var sum float32
+			for l := 0; l < n; l++ {
+				sum += float32(temp[i*n+l] * d[j*n+l])
+			}
+			result[i*k+j] = sum
+		}
+	}
+	return result, nil
+}
@@ -2,6 +2,7 @@ package dct
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"slices"
 )
@@ -11,48 +12,45 @@ var (
 	ErrInvalidSize  = errors.New("expect 1d or NxN 2d arrays")
 )
 
-func DCT(vector [][]float64) ([][]float64, error) {
-	if len(vector) == 0 || len(vector[0]) == 0 {
-		return nil, ErrInvalidInput
-	}
+// SizeError reports an input of the wrong length for a fixed size transform.
+// It matches ErrInvalidSize with errors.Is.
+type SizeError struct {
+	Func string
+	Size int // side of the expected Size x Size input
+	Len  int // length of the input given
+}
 
-	dim := len(vector)
-	sz := len(vector[0])
-	if dim != 1 && dim != sz {
-		return nil, ErrInvalidSize
-	}
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("%s: incorrect input size %d, wanted %dx%d", e.Func, e.Len, e.Size, e.Size)
+}
 
-	pack := flatten(vector)
+func (e *SizeError) Unwrap() error {
+	return ErrInvalidSize
+}
 
-	if dim > 1 {
-		if sz == 8 {
-			fct8_2d(pack)
-		} else if (sz & (sz - 1)) == 0 {
-			fast_dct_2d(pack, sz)
-		} else {
-			dct_2d(pack, sz)
-		}
-	} else {
-		if sz == 8 {
-			fct8_1d(pack)
-		} else if (sz & (sz - 1)) == 0 {
-			fast_dct_1d(pack, sz)
-		} else {
-			dct_1d(pack, sz)
-		}
+// check_size returns ErrInvalidInput for an empty input and a *SizeError if
+// input is not size x size.
+func check_size(name string, input []float64, size int) error {
+	if len(input) == 0 {
+		return ErrInvalidInput
 	}
+	if len(input) != size*size {
+		return &SizeError{Func: name, Size: size, Len: len(input)}
+	}
+	return nil
+}
 
-	result := make([][]float64, dim)
-	for i := 0; i < dim; i++ {
-		result[i] = make([]float64, sz)
+// DCT returns the DCT-II of a 1 x N or M x N input, see DCTMatrix.
+func DCT(vector [][]float64) ([][]float64, error) {
+	m, err := MatrixOf(vector)
+	if err != nil {
+		return nil, err
 	}
 
-	for i := 0; i < dim; i++ {
-		for j := 0; j < sz; j++ {
-			result[i][j] = pack[(i*sz + j)]
-		}
+	if err := DCTMatrix(m); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return m.Slices(), nil
 }
 
 func DCT_1D(input []float64, sz int) []float64 {
@@ -68,6 +66,11 @@ func DCT_1D(input []float64, sz int) []float64 {
 		return result
 	}
 
+	if use_fft_1d(sz) {
+		fft_dct_kernel[float64](sz, false)(result) // Makhoul
+		return result
+	}
+
 	dct_1d(result, sz)
 	return result
 }
@@ -75,6 +78,16 @@ func DCT_1D(input []float64, sz int) []float64 {
 func IDCT_1D(input []float64, sz int) []float64 {
 	result := slices.Clone(input)
 
+	if sz > 0 && (sz&(sz-1)) == 0 {
+		fast_idct_1d(result, sz)
+		return result
+	}
+
+	if use_fft_1d(sz) {
+		fft_dct_kernel[float64](sz, true)(result)
+		return result
+	}
+
 	idct_1d(result, sz)
 
 	return result
@@ -121,6 +134,12 @@ func DCT_2D(input []float64, sz int) []float64 {
 		return result
 	}
 
+	if sz >= fft_threshold {
+		kernel := fft_dct_kernel[float64](sz, false)
+		separable_2d(result, sz, sz, kernel, kernel)
+		return result
+	}
+
 	dct_2d(result, sz)
 	return result
 }
@@ -131,6 +150,18 @@ func IDCT_2D(input []float64, sz int) []float64 {
 	}
 
 	result := slices.Clone(input)
+	if (sz & (sz - 1)) == 0 { // power of 2
+		kernel := idct_1d_kernel[float64](sz)
+		separable_2d(result, sz, sz, kernel, kernel)
+		return result
+	}
+
+	if sz >= fft_threshold {
+		kernel := fft_dct_kernel[float64](sz, true)
+		separable_2d(result, sz, sz, kernel, kernel)
+		return result
+	}
+
 	idct_2d(result, sz)
 
 	//result := make([]float64, sz*sz)
@@ -140,10 +171,56 @@ func IDCT_2D(input []float64, sz int) []float64 {
 	return result
 }
 
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// DCT_MxN returns the DCT-II of a flattened input of rows x cols. Each axis
+// uses the fast transform when its length is a power of 2, or an FFT when it
+// is long, so images can be transformed without resizing them to a square
+// first.
+func DCT_MxN(input []float64, rows, cols int) ([]float64, error) {
+	if rows <= 0 || cols <= 0 || len(input) != rows*cols {
+		return nil, ErrInvalidInput
+	}
+
+	if rows == cols {
+		return DCT_2D(input, rows), nil
+	}
+
+	result := slices.Clone(input)
+	separable_2d(result, rows, cols, dct_1d_kernel[float64](cols), dct_1d_kernel[float64](rows))
+	return result, nil
+}
+
+// IDCT_MxN returns the inverse of DCT_MxN for a flattened input of rows x cols.
+func IDCT_MxN(input []float64, rows, cols int) ([]float64, error) {
+	if rows <= 0 || cols <= 0 || len(input) != rows*cols {
+		return nil, ErrInvalidInput
+	}
+
+	if rows == cols {
+		return IDCT_2D(input, rows), nil
+	}
+
+	result := slices.Clone(input)
+	separable_2d(result, rows, cols, idct_1d_kernel[float64](cols), idct_1d_kernel[float64](rows))
+	return result, nil
+}
+
+// Fast uses static DCT tables for improved performance. Returns the
+// coefficients flattened row by row, flattens[8*v+u] for vertical frequency
+// v and horizontal frequency u. The row pass is done in place in input.
+// Panics if input is not 8x8, see DCT2DFast8E.
 func DCT2DFast8(input []float64) (flattens [8 * 8]float64) {
-	if len(input) != 8*8 {
-		panic("incorrect input size, wanted 8x8.")
+	flattens, err := DCT2DFast8E(input)
+	if err != nil {
+		panic(err)
+	}
+	return flattens
+}
+
+// DCT2DFast8E is DCT2DFast8 returning an error instead of panicking on an
+// input that is not 8x8.
+func DCT2DFast8E(input []float64) (flattens [8 * 8]float64, err error) {
+	if err := check_size("DCT2DFast8", input, 8); err != nil {
+		return flattens, err
 	}
 
 	for i := 0; i < 8; i++ { // height
@@ -160,13 +237,26 @@ func DCT2DFast8(input []float64) (flattens [8 * 8]float64) {
 			flattens[8*j+i] = row[j]
 		}
 	}
-	return flattens
+	return flattens, nil
 }
 
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// Fast uses static DCT tables for improved performance. Returns the
+// coefficients flattened row by row, flattens[16*v+u] for vertical frequency
+// v and horizontal frequency u. The row pass is done in place in input.
+// Panics if input is not 16x16, see DCT2DFast16E.
 func DCT2DFast16(input []float64) (flattens [16 * 16]float64) {
-	if len(input) != 16*16 {
-		panic("incorrect input size, wanted 16x16.")
+	flattens, err := DCT2DFast16E(input)
+	if err != nil {
+		panic(err)
+	}
+	return flattens
+}
+
+// DCT2DFast16E is DCT2DFast16 returning an error instead of panicking on an
+// input that is not 16x16.
+func DCT2DFast16E(input []float64) (flattens [16 * 16]float64, err error) {
+	if err := check_size("DCT2DFast16", input, 16); err != nil {
+		return flattens, err
 	}
 
 	for i := 0; i < 16; i++ { // height
@@ -183,13 +273,30 @@ func DCT2DFast16(input []float64) (flattens [16 * 16]float64) {
 			flattens[16*j+i] = row[j]
 		}
 	}
-	return
+	return flattens, nil
 }
 
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// Fast uses static DCT tables for improved performance. Returns the
+// coefficients flattened row by row, flattens[32*v+u] for vertical frequency
+// v and horizontal frequency u. The row pass is done in place in input.
+// Panics if input is not 32x32, see DCT2DFast32E.
 func DCT2DFast32(input []float64) (flattens [32 * 32]float64) {
-	if len(input) != 32*32 {
-		panic("incorrect input size, wanted 32x32.")
+	flattens, err := DCT2DFast32E(input)
+	if err != nil {
+		panic(err)
+	}
+	return flattens
+}
+
+// DCT2DFast32E is DCT2DFast32 returning an error instead of panicking on an
+// input that is not 32x32.
+func DCT2DFast32E(input []float64) (flattens [32 * 32]float64, err error) {
+	if err := check_size("DCT2DFast32", input, 32); err != nil {
+		return flattens, err
+	}
+
+	if dct32_2d_asm(input, &flattens) {
+		return flattens, nil
 	}
 
 	for i := 0; i < 32; i++ { // height
@@ -206,13 +313,26 @@ func DCT2DFast32(input []float64) (flattens [32 * 32]float64) {
 			flattens[32*j+i] = row[j]
 		}
 	}
-	return
+	return flattens, nil
 }
 
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// Fast uses static DCT tables for improved performance. Returns the
+// coefficients flattened row by row, flattens[64*v+u] for vertical frequency
+// v and horizontal frequency u. The row pass is done in place in input.
+// Panics if input is not 64x64, see DCT2DFast64E.
 func DCT2DFast64(input []float64) (flattens [4096]float64) {
-	if len(input) != 64*64 {
-		panic("incorrect input size, wanted 64x64.")
+	flattens, err := DCT2DFast64E(input)
+	if err != nil {
+		panic(err)
+	}
+	return flattens
+}
+
+// DCT2DFast64E is DCT2DFast64 returning an error instead of panicking on an
+// input that is not 64x64.
+func DCT2DFast64E(input []float64) (flattens [4096]float64, err error) {
+	if err := check_size("DCT2DFast64", input, 64); err != nil {
+		return flattens, err
 	}
 
 	for i := 0; i < 64; i++ { // height
@@ -229,13 +349,26 @@ func DCT2DFast64(input []float64) (flattens [4096]float64) {
 			flattens[64*j+i] = row[j]
 		}
 	}
-	return
+	return flattens, nil
 }
 
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// Fast uses static DCT tables for improved performance. Returns the
+// coefficients flattened row by row, flattens[128*v+u] for vertical frequency
+// v and horizontal frequency u. The row pass is done in place in input.
+// Panics if input is not 128x128, see DCT2DFast128E.
 func DCT2DFast128(input []float64) (flattens [128 * 128]float64) {
-	if len(input) != 128*128 {
-		panic("incorrect input size, wanted 128x128.")
+	flattens, err := DCT2DFast128E(input)
+	if err != nil {
+		panic(err)
+	}
+	return flattens
+}
+
+// DCT2DFast128E is DCT2DFast128 returning an error instead of panicking on an
+// input that is not 128x128.
+func DCT2DFast128E(input []float64) (flattens [128 * 128]float64, err error) {
+	if err := check_size("DCT2DFast128", input, 128); err != nil {
+		return flattens, err
 	}
 
 	for i := 0; i < 128; i++ { // height
@@ -252,13 +385,26 @@ func DCT2DFast128(input []float64) (flattens [128 * 128]float64) {
 			flattens[128*j+i] = row[j]
 		}
 	}
-	return
+	return flattens, nil
 }
 
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// Fast uses static DCT tables for improved performance. Returns the
+// coefficients flattened row by row, flattens[256*v+u] for vertical frequency
+// v and horizontal frequency u. The row pass is done in place in input.
+// Panics if input is not 256x256, see DCT2DFast256E.
 func DCT2DFast256(input []float64) (flattens [256 * 256]float64) {
-	if len(input) != 256*256 {
-		panic("incorrect input size, wanted 256x256.")
+	flattens, err := DCT2DFast256E(input)
+	if err != nil {
+		panic(err)
+	}
+	return flattens
+}
+
+// DCT2DFast256E is DCT2DFast256 returning an error instead of panicking on an
+// input that is not 256x256.
+func DCT2DFast256E(input []float64) (flattens [256 * 256]float64, err error) {
+	if err := check_size("DCT2DFast256", input, 256); err != nil {
+		return flattens, err
 	}
 
 	for i := 0; i < 256; i++ { // height
@@ -275,7 +421,7 @@ func DCT2DFast256(input []float64) (flattens [256 * 256]float64) {
 			flattens[256*j+i] = row[j]
 		}
 	}
-	return
+	return flattens, nil
 }
 
 // flatten [][] to [] to match convention ported from perl Math::DCT
@@ -285,8 +431,76 @@ func flatten(in [][]float64) []float64 {
 	out := make([]float64, h*w)
 	for i, row := range in {
 		for j, col := range row {
-			out[i*h+j] = col
+			out[i*w+j] = col
 		}
 	}
 	return out
 }
+
+// PartialDCT2D computes only the top-left kxk coefficients of the 2D DCT-II of
+// the nxn input. Rows are transformed in full but only the first k columns of
+// the row pass are kept and transformed, which is all a perceptual hash needs.
+// Returns flattened kxk coefficients; src is not modified.
+func PartialDCT2D(src []float64, n, k int) ([]float64, error) {
+	if n <= 0 || len(src) != n*n {
+		return nil, ErrInvalidInput
+	}
+	if k <= 0 || k > n {
+		return nil, ErrInvalidSize
+	}
+
+	kernel := partial_dct_kernel(n, k)
+
+	rows := make([]float64, n*k)
+	for y := 0; y < n; y++ {
+		kernel(src[y*n:(y+1)*n], rows[y*k:(y+1)*k])
+	}
+
+	result := make([]float64, k*k)
+	col := make([]float64, n)
+	out := make([]float64, k)
+	for x := 0; x < k; x++ {
+		for y := 0; y < n; y++ {
+			col[y] = rows[y*k+x]
+		}
+		kernel(col, out)
+		for y := 0; y < k; y++ {
+			result[y*k+x] = out[y]
+		}
+	}
+	return result, nil
+}
+
+// partial_dct_kernel returns a 1D transform writing the first k coefficients
+// of an n point DCT-II into out. Power of 2 sizes use the full fast transform,
+// other sizes only compute the k coefficients needed.
+func partial_dct_kernel(n, k int) func(in, out []float64) {
+	if n&(n-1) == 0 {
+		fast := dct_1d_kernel[float64](n)
+		temp := make([]float64, n)
+		return func(in, out []float64) {
+			copy(temp, in)
+			fast(temp)
+			copy(out, temp[:k])
+		}
+	}
+
+	coef := make([]float64, n*k)
+	factor := math.Pi / float64(n)
+	for i := 0; i < k; i++ {
+		mult := float64(i) * factor
+		for j := 0; j < n; j++ {
+			coef[i*n+j] = math.Cos((float64(j) + 0.5) * mult)
+		}
+	}
+	return func(in, out []float64) {
+		for i := 0; i < k; i++ {
+			sum := 0.0
+			c := coef[i*n : (i+1)*n]
+			for j := 0; j < n; j++ {
+				sum += in[j] * c[j]
+			}
+			out[i] = sum
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package dct
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -108,6 +112,11 @@ func TestIDCT_1D(t *testing.T) {
 			t.Errorf("IDCT_1D(%v) expected %v but got %v.", tt.output, tt.input, in)
 		}
 	}
+
+	// a size of 0 transforms nothing, and 0 is not taken as a power of 2
+	if in := IDCT_1D(ary[4], 0); !slices.Equal(in, ary[4]) {
+		t.Errorf("IDCT_1D(%v, 0) expected %v but got %v.", ary[4], ary[4], in)
+	}
 }
 
 func TestDCT_2D(t *testing.T) {
@@ -376,6 +385,1040 @@ func TestDCT(t *testing.T) {
 	}
 }
 
+func TestPartialDCT2D(t *testing.T) {
+	for _, tt := range []struct {
+		input  [][]float64
+		output [][]float64
+		n      int
+		k      int
+	}{
+		{ary2d[3], exp2d[3], 3, 2},
+		{ary2d[4], exp2d[4], 4, 4},
+		{ary2d[8], exp2d[8], 8, 8},
+		{ary2d[11], exp2d[11], 11, 5},
+		{ary2d[16], exp2d[16], 16, 8},
+		{ary2d[32], exp2d[32], 32, 8},
+		{ary2d[64], exp2d[64], 64, 16},
+		{ary2d[128], exp2d[128], 128, 8},
+		{ary2d[256], exp2d[256], 256, 16},
+	} {
+		flat_in := flatten(tt.input)
+		out, err := PartialDCT2D(flat_in, tt.n, tt.k)
+		if err != nil {
+			t.Fatalf("PartialDCT2D(%d, %d) returned error %v", tt.n, tt.k, err)
+		}
+		pass := len(out) == tt.k*tt.k
+
+		for i := 0; pass && i < tt.k; i++ {
+			for j := 0; j < tt.k; j++ {
+				if math.Abs(out[i*tt.k+j]-tt.output[i][j]) > EPSILON {
+					pass = false
+				}
+			}
+		}
+
+		if !pass {
+			t.Errorf("PartialDCT2D(%d, %d)\n\texpected top left of %v\n\n\tbut got %v.", tt.n, tt.k, tt.output, out)
+		}
+	}
+
+	for _, tt := range []struct {
+		input []float64
+		n     int
+		k     int
+		err   error
+	}{
+		{nil, 0, 0, ErrInvalidInput},
+		{ary2d_flat[8], 16, 8, ErrInvalidInput},
+		{ary2d_flat[8], 8, 0, ErrInvalidSize},
+		{ary2d_flat[8], 8, 9, ErrInvalidSize},
+	} {
+		if _, err := PartialDCT2D(tt.input, tt.n, tt.k); err != tt.err {
+			t.Errorf("PartialDCT2D(%d, %d) expected error %v but got %v.", tt.n, tt.k, tt.err, err)
+		}
+	}
+}
+
+func TestPartialDCT2DF32(t *testing.T) {
+	for _, tt := range []struct {
+		n, from, k int
+	}{
+		{3, 0, 3},
+		{4, 1, 2},
+		{8, 0, 8},
+		{11, 2, 5},
+		{16, 1, 8},
+		{64, 1, 16},
+		{128, 0, 8},
+	} {
+		in32 := make([]float32, tt.n*tt.n)
+		l1 := 0.0
+		for i, v := range ary2d_flat[tt.n] {
+			in32[i] = float32(v)
+			l1 += math.Abs(v)
+		}
+		out, err := PartialDCT2DF32(in32, tt.n, tt.from, tt.k)
+		if err != nil {
+			t.Fatalf("PartialDCT2DF32(%d, %d, %d) returned error %v", tt.n, tt.from, tt.k, err)
+		}
+		if len(out) != tt.k*tt.k {
+			t.Fatalf("PartialDCT2DF32(%d, %d, %d) returned %d values, wanted %d.", tt.n, tt.from, tt.k, len(out), tt.k*tt.k)
+		}
+
+		scale := 2 / float64(tt.n)
+		tolerance := 2.4e-7 * l1
+		for i := 0; i < tt.k; i++ {
+			for j := 0; j < tt.k; j++ {
+				expected := exp2d[tt.n][tt.from+i][tt.from+j] * scale
+				if got := float64(out[i*tt.k+j]); math.Abs(got-expected) > tolerance {
+					t.Errorf("PartialDCT2DF32(%d, %d, %d)[%d][%d] expected %v but got %v.", tt.n, tt.from, tt.k, i, j, expected, got)
+				}
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		input      []float32
+		n, from, k int
+		err        error
+	}{
+		{nil, 0, 0, 1, ErrInvalidInput},
+		{make([]float32, 64), 16, 0, 8, ErrInvalidInput},
+		{make([]float32, 64), 8, 0, 0, ErrInvalidSize},
+		{make([]float32, 64), 8, -1, 4, ErrInvalidSize},
+		{make([]float32, 64), 8, 1, 8, ErrInvalidSize},
+	} {
+		if _, err := PartialDCT2DF32(tt.input, tt.n, tt.from, tt.k); err != tt.err {
+			t.Errorf("PartialDCT2DF32(%d, %d, %d) expected error %v but got %v.", tt.n, tt.from, tt.k, tt.err, err)
+		}
+	}
+}
+
+func TestDCT_MxN(t *testing.T) {
+	r := rand.New(rand.NewSource(27))
+	for _, tt := range []struct {
+		rows int
+		cols int
+	}{
+		{1, 8},
+		{8, 1},
+		{3, 5},
+		{4, 8},
+		{8, 4},
+		{8, 11},
+		{11, 16},
+		{16, 32},
+		{32, 64},
+		{48, 64},
+		{8, 8},
+		{11, 11},
+	} {
+		input := make([][]float64, tt.rows)
+		for i := range input {
+			input[i] = make([]float64, tt.cols)
+			for j := range input[i] {
+				input[i][j] = r.Float64()
+			}
+		}
+		expect := naive_dct_mxn(input)
+		flat_in := flatten(input)
+
+		out, err := DCT_MxN(flat_in, tt.rows, tt.cols)
+		if err != nil {
+			t.Fatalf("DCT_MxN(%d, %d) returned error %v", tt.rows, tt.cols, err)
+		}
+		for i := 0; i < tt.rows; i++ {
+			for j := 0; j < tt.cols; j++ {
+				if math.Abs(out[i*tt.cols+j]-expect[i][j]) > EPSILON {
+					t.Fatalf("DCT_MxN(%d, %d)\n\texpected %v\n\n\tbut got %v.", tt.rows, tt.cols, expect, out)
+				}
+			}
+		}
+
+		in, err := IDCT_MxN(out, tt.rows, tt.cols)
+		if err != nil {
+			t.Fatalf("IDCT_MxN(%d, %d) returned error %v", tt.rows, tt.cols, err)
+		}
+		for i := range in {
+			if math.Abs(in[i]-flat_in[i]) > EPSILON {
+				t.Fatalf("IDCT_MxN(%d, %d)\n\texpected %v\n\n\tbut got %v.", tt.rows, tt.cols, flat_in, in)
+			}
+		}
+
+		if tt.rows == 1 {
+			continue
+		}
+		nested, err := DCT(input)
+		if err != nil {
+			t.Fatalf("DCT(%d, %d) returned error %v", tt.rows, tt.cols, err)
+		}
+		for i := 0; i < tt.rows; i++ {
+			for j := 0; j < tt.cols; j++ {
+				if math.Abs(nested[i][j]-expect[i][j]) > EPSILON {
+					t.Fatalf("DCT(%d, %d)\n\texpected %v\n\n\tbut got %v.", tt.rows, tt.cols, expect, nested)
+				}
+			}
+		}
+	}
+
+	if _, err := DCT_MxN(ary2d_flat[8], 4, 8); err != ErrInvalidInput {
+		t.Errorf("DCT_MxN(4, 8) of 64 values expected error %v but got %v.", ErrInvalidInput, err)
+	}
+	if _, err := DCT([][]float64{{1, 2, 3}, {4, 5}}); err != ErrInvalidSize {
+		t.Errorf("DCT of ragged rows expected error %v but got %v.", ErrInvalidSize, err)
+	}
+}
+
+// TestF32 bounds the float32 transforms against float64 by float32 epsilon
+// scaled by the L1 norm of the input, the worst case growth of a sum.
+func TestF32(t *testing.T) {
+	for _, n := range []int{3, 4, 8, 11, 16, 32, 64, 128, 256} {
+		for _, tt := range []struct {
+			name  string
+			input []float64
+			f64   func([]float64, int) []float64
+			f32   func([]float32, int) []float32
+		}{
+			{"DCT_1D", ary[n], DCT_1D, DCT_1D_F32},
+			{"IDCT_1D", exp[n], IDCT_1D, IDCT_1D_F32},
+			{"DCT_2D", ary2d_flat[n], DCT_2D, DCT_2D_F32},
+			{"IDCT_2D", flatten(exp2d[n]), IDCT_2D, IDCT_2D_F32},
+		} {
+			in32 := make([]float32, len(tt.input))
+			l1 := 0.0
+			for i, v := range tt.input {
+				in32[i] = float32(v)
+				l1 += math.Abs(v)
+			}
+			out64 := tt.f64(tt.input, n)
+			out32 := tt.f32(in32, n)
+			tolerance := 1.2e-7 * l1
+
+			if len(out32) != len(out64) {
+				t.Fatalf("%s_F32(%d) returned %d values, wanted %d.", tt.name, n, len(out32), len(out64))
+			}
+			for i := range out64 {
+				if math.Abs(out64[i]-float64(out32[i])) > tolerance {
+					t.Errorf("%s_F32(%d)[%d] = %v, float64 gives %v, tolerance %v.", tt.name, n, i, out32[i], out64[i], tolerance)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestNorm(t *testing.T) {
+	// scipy.fft.dct([1, 2, 3, 4], norm='ortho')
+	ortho := DCT_1D_Norm([]float64{1, 2, 3, 4}, 4, NormOrtho)
+	for i, v := range []float64{5, -2.230442497387663, 0, -0.15851266778110726} {
+		if math.Abs(ortho[i]-v) > EPSILON {
+			t.Fatalf("DCT_1D_Norm(NormOrtho) expected %v but got %v.", v, ortho)
+		}
+	}
+
+	// T.81 DC of a flat 8x8 block is 8 times its level
+	flat := make([]float64, 64)
+	for i := range flat {
+		flat[i] = 128
+	}
+	jpeg := DCT_2D_Norm(flat, 8, NormJPEG)
+	for i, v := range jpeg {
+		if (i == 0 && math.Abs(v-1024) > EPSILON) || (i != 0 && math.Abs(v) > EPSILON) {
+			t.Fatalf("DCT_2D_Norm(NormJPEG) of flat block gave %v.", jpeg)
+		}
+	}
+
+	for _, n := range []int{3, 4, 8, 11, 16, 32, 64} {
+		// orthonormal transforms preserve energy
+		var energy_in, energy_1d, energy_2d float64
+		out_1d := DCT_1D_Norm(ary[n], n, NormOrtho)
+		out_2d := DCT_2D_Norm(ary2d_flat[n], n, NormOrtho)
+		for i := range out_1d {
+			energy_in += ary[n][i] * ary[n][i]
+			energy_1d += out_1d[i] * out_1d[i]
+		}
+		if math.Abs(energy_in-energy_1d) > EPSILON {
+			t.Errorf("DCT_1D_Norm(%d, NormOrtho) energy %v, input energy %v.", n, energy_1d, energy_in)
+		}
+		energy_in = 0
+		for i := range out_2d {
+			energy_in += ary2d_flat[n][i] * ary2d_flat[n][i]
+			energy_2d += out_2d[i] * out_2d[i]
+		}
+		if math.Abs(energy_in-energy_2d) > EPSILON*float64(n) {
+			t.Errorf("DCT_2D_Norm(%d, NormOrtho) energy %v, input energy %v.", n, energy_2d, energy_in)
+		}
+
+		if n == 8 {
+			jpeg := DCT_2D_Norm(ary2d_flat[n], n, NormJPEG)
+			for i := range jpeg {
+				if math.Abs(jpeg[i]-out_2d[i]) > EPSILON {
+					t.Fatalf("DCT_2D_Norm(8, NormJPEG) expected %v but got %v.", out_2d, jpeg)
+				}
+			}
+		}
+
+		for _, norm := range []Norm{NormNone, NormOrtho, NormJPEG} {
+			in := IDCT_1D_Norm(DCT_1D_Norm(ary[n], n, norm), n, norm)
+			for i := range in {
+				if math.Abs(in[i]-ary[n][i]) > EPSILON {
+					t.Fatalf("IDCT_1D_Norm(DCT_1D_Norm(%d, %d)) expected %v but got %v.", n, norm, ary[n], in)
+				}
+			}
+
+			in = IDCT_2D_Norm(DCT_2D_Norm(ary2d_flat[n], n, norm), n, norm)
+			for i := range in {
+				if math.Abs(in[i]-ary2d_flat[n][i]) > EPSILON {
+					t.Fatalf("IDCT_2D_Norm(DCT_2D_Norm(%d, %d)) expected %v but got %v.", n, norm, ary2d_flat[n], in)
+				}
+			}
+		}
+	}
+
+	fast := DCT2DFast32(flatten(ary2d[32]))
+	Normalize(fast[:], 32, 32, NormOrtho)
+	out := DCT_2D_Norm(ary2d_flat[32], 32, NormOrtho)
+	for i := range out {
+		if math.Abs(fast[i]-out[i]) > EPSILON {
+			t.Fatalf("Normalize(DCT2DFast32, NormOrtho) expected %v but got %v.", out, fast)
+		}
+	}
+}
+
+func TestFFTDCT(t *testing.T) {
+	r := rand.New(rand.NewSource(38))
+	sizes := []int{241, 243, 250, 500, 509}
+	for n := 1; n <= 130; n++ {
+		sizes = append(sizes, n)
+	}
+
+	for _, n := range sizes {
+		input := make([]float64, n)
+		for i := range input {
+			input[i] = r.Float64()*2 - 1
+		}
+		tolerance := 1e-12 * float64(n)
+
+		expect := slices.Clone(input)
+		dct_1d(expect, n)
+		out := slices.Clone(input)
+		fft_dct_kernel[float64](n, false)(out)
+		for i := range out {
+			if math.Abs(out[i]-expect[i]) > tolerance {
+				t.Fatalf("fft DCT(%d)[%d] expected %v but got %v.", n, i, expect[i], out[i])
+			}
+		}
+
+		expect = slices.Clone(input)
+		idct_1d(expect, n)
+		out = slices.Clone(input)
+		fft_dct_kernel[float64](n, true)(out)
+		for i := range out {
+			if math.Abs(out[i]-expect[i]) > tolerance {
+				t.Fatalf("fft IDCT(%d)[%d] expected %v but got %v.", n, i, expect[i], out[i])
+			}
+		}
+	}
+
+	// DCT_1D and IDCT_1D take the FFT from 11 unless Bluestein is needed
+	for n, want := range map[int]bool{10: false, 11: true, 12: true, 17: false, 47: false, 48: true, 61: true} {
+		if got := use_fft_1d(n); got != want {
+			t.Errorf("use_fft_1d(%d) expected %v but got %v.", n, want, got)
+		}
+	}
+
+	// sizes picked up automatically, mixed radix and Bluestein
+	for _, n := range []int{48, 61, 100} {
+		input := make([]float64, n*n)
+		input32 := make([]float32, n*n)
+		l1 := 0.0
+		for i := range input {
+			input[i] = r.Float64() * 255
+			input32[i] = float32(input[i])
+			l1 += input[i]
+		}
+
+		expect := slices.Clone(input)
+		dct_2d(expect, n)
+		out := DCT_2D(input, n)
+		out32 := DCT_2D_F32(input32, n)
+		for i := range out {
+			if math.Abs(out[i]-expect[i]) > 1e-7 {
+				t.Fatalf("DCT_2D(%d)[%d] expected %v but got %v.", n, i, expect[i], out[i])
+			}
+			if math.Abs(float64(out32[i])-expect[i]) > 1.2e-7*l1 {
+				t.Fatalf("DCT_2D_F32(%d)[%d] expected %v but got %v.", n, i, expect[i], out32[i])
+			}
+		}
+
+		back := IDCT_2D(out, n)
+		for i := range back {
+			if math.Abs(back[i]-input[i]) > 1e-9 {
+				t.Fatalf("IDCT_2D(DCT_2D(%d))[%d] expected %v but got %v.", n, i, input[i], back[i])
+			}
+		}
+
+		row := DCT_1D(input[:n], n)
+		expect = slices.Clone(input[:n])
+		dct_1d(expect, n)
+		for i := range row {
+			if math.Abs(row[i]-expect[i]) > 1e-9 {
+				t.Fatalf("DCT_1D(%d)[%d] expected %v but got %v.", n, i, expect[i], row[i])
+			}
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	r := rand.New(rand.NewSource(30))
+	for _, tt := range []struct {
+		name    string
+		fast    func([]float64, int)
+		naive   func([]float64, int)
+		inverse func([]float64, int) ([]float64, error)
+		scale   func(n int) float64
+		sizes   []int
+	}{
+		{"DCTI_1D", fast_dct1_1d[float64], dct1_1d[float64], DCTI_1D, func(n int) float64 { return 2 / float64(n-1) }, []int{2, 3, 5, 9, 17, 33, 65, 257}},
+		{"DCTIV_1D", fast_dct4_1d[float64], dct4_1d[float64], DCTIV_1D, func(n int) float64 { return 2 / float64(n) }, []int{1, 2, 4, 8, 16, 32, 64, 256}},
+		{"DSTI_1D", fast_dst1_1d[float64], dst1_1d[float64], DSTI_1D, func(n int) float64 { return 2 / float64(n+1) }, []int{1, 3, 7, 15, 31, 63, 255}},
+		{"DSTII_1D", fast_dst2_1d[float64], dst2_1d[float64], DSTIII_1D, func(n int) float64 { return 2 / float64(n) }, []int{1, 2, 4, 8, 16, 32, 64, 256}},
+		{"DSTIII_1D", fast_dst3_1d[float64], dst3_1d[float64], DSTII_1D, func(n int) float64 { return 2 / float64(n) }, []int{1, 2, 4, 8, 16, 32, 64, 256}},
+		{"DSTIV_1D", fast_dst4_1d[float64], dst4_1d[float64], DSTIV_1D, func(n int) float64 { return 2 / float64(n) }, []int{1, 2, 4, 8, 16, 32, 64, 256}},
+	} {
+		for _, n := range append(tt.sizes, 6, 11) {
+			input := make([]float64, n)
+			for i := range input {
+				input[i] = r.Float64()
+			}
+
+			expect := slices.Clone(input)
+			tt.naive(expect, n)
+
+			if slices.Contains(tt.sizes, n) {
+				out := slices.Clone(input)
+				tt.fast(out, n)
+				for i := range out {
+					if math.Abs(out[i]-expect[i]) > EPSILON*float64(n) {
+						t.Fatalf("fast %s(%d)\n\texpected %v\n\n\tbut got %v.", tt.name, n, expect, out)
+					}
+				}
+			}
+
+			in, err := tt.inverse(expect, n)
+			if err != nil {
+				t.Fatalf("inverse of %s(%d) returned error %v", tt.name, n, err)
+			}
+			for i := range in {
+				if math.Abs(in[i]*tt.scale(n)-input[i]) > EPSILON*float64(n) {
+					t.Fatalf("inverse of %s(%d)\n\texpected %v\n\n\tbut got %v.", tt.name, n, input, in)
+				}
+			}
+		}
+	}
+
+	out, _ := DSTII_1D([]float64{1, 1}, 2)
+	if math.Abs(out[0]-math.Sqrt2) > EPSILON || math.Abs(out[1]) > EPSILON {
+		t.Errorf("DSTII_1D([1 1]) expected [%v 0] but got %v.", math.Sqrt2, out)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		transform func([]float64, int) ([]float64, error)
+		input     []float64
+		sz        int
+	}{
+		{"DCTI_1D", DCTI_1D, nil, 0},
+		{"DCTI_1D", DCTI_1D, []float64{1}, 1},
+		{"DCTIV_1D", DCTIV_1D, nil, 0},
+		{"DSTI_1D", DSTI_1D, nil, 0},
+		{"DSTII_1D", DSTII_1D, nil, 0},
+		{"DSTIII_1D", DSTIII_1D, nil, 0},
+		{"DSTIV_1D", DSTIV_1D, []float64{1}, -1},
+		{"DSTIV_1D", DSTIV_1D, []float64{1, 2}, 4},
+	} {
+		if _, err := tt.transform(tt.input, tt.sz); err != ErrInvalidInput {
+			t.Errorf("%s(%v, %d) expected error %v but got %v.", tt.name, tt.input, tt.sz, ErrInvalidInput, err)
+		}
+	}
+}
+
+func TestBlockDCT2D(t *testing.T) {
+	r := rand.New(rand.NewSource(31))
+	for _, tt := range []struct {
+		width  int
+		height int
+		n      int
+	}{
+		{8, 8, 8},
+		{64, 48, 8},
+		{48, 64, 16},
+		{33, 22, 11},
+		{96, 32, 32},
+	} {
+		plane := make([]float64, tt.width*tt.height)
+		for i := range plane {
+			plane[i] = r.Float64() * 255
+		}
+		out := slices.Clone(plane)
+		if err := BlockDCT2D(out, tt.width, tt.height, tt.n); err != nil {
+			t.Fatalf("BlockDCT2D(%d, %d, %d) returned error %v", tt.width, tt.height, tt.n, err)
+		}
+
+		block := make([]float64, tt.n*tt.n)
+		for by := 0; by < tt.height; by += tt.n {
+			for bx := 0; bx < tt.width; bx += tt.n {
+				for y := 0; y < tt.n; y++ {
+					copy(block[y*tt.n:(y+1)*tt.n], plane[(by+y)*tt.width+bx:])
+				}
+				expect := DCT_2D(block, tt.n)
+				for y := 0; y < tt.n; y++ {
+					for x := 0; x < tt.n; x++ {
+						if math.Abs(out[(by+y)*tt.width+bx+x]-expect[y*tt.n+x]) > EPSILON*255 {
+							t.Fatalf("BlockDCT2D(%d, %d, %d) block at %d,%d\n\texpected %v\n\n\tbut got %v.", tt.width, tt.height, tt.n, bx, by, expect, out)
+						}
+					}
+				}
+			}
+		}
+
+		if err := BlockIDCT2D(out, tt.width, tt.height, tt.n); err != nil {
+			t.Fatalf("BlockIDCT2D(%d, %d, %d) returned error %v", tt.width, tt.height, tt.n, err)
+		}
+		for i := range out {
+			if math.Abs(out[i]-plane[i]) > EPSILON*255 {
+				t.Fatalf("BlockIDCT2D(%d, %d, %d)\n\texpected %v\n\n\tbut got %v.", tt.width, tt.height, tt.n, plane, out)
+			}
+		}
+	}
+
+	if err := BlockDCT2D(make([]float64, 12*8), 12, 8, 8); err != ErrInvalidSize {
+		t.Errorf("BlockDCT2D(12, 8, 8) expected error %v but got %v.", ErrInvalidSize, err)
+	}
+	if err := BlockDCT2D(make([]float64, 10), 8, 8, 8); err != ErrInvalidInput {
+		t.Errorf("BlockDCT2D of 10 values expected error %v but got %v.", ErrInvalidInput, err)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	r := rand.New(rand.NewSource(36))
+	image := NewMatrix(40, 50)
+	for i := range image.Data {
+		image.Data[i] = r.Float64() * 255
+	}
+
+	for _, tt := range []struct {
+		i, j, rows, cols int
+	}{
+		{3, 5, 8, 8},
+		{0, 0, 16, 32},
+		{7, 2, 11, 5},
+		{39, 10, 1, 16},
+		{20, 49, 12, 1},
+	} {
+		orig := slices.Clone(image.Data)
+		view := image.Sub(tt.i, tt.j, tt.rows, tt.cols)
+		window := make([]float64, 0, tt.rows*tt.cols)
+		for y := 0; y < tt.rows; y++ {
+			window = append(window, view.Row(y)...)
+		}
+
+		var expect []float64
+		if tt.rows == 1 {
+			expect = DCT_1D(window, tt.cols)
+		} else {
+			expect = flatten(naive_dct_mxn(Matrix{Data: window, Rows: tt.rows, Cols: tt.cols, Stride: tt.cols}.Slices()))
+		}
+
+		if err := DCTMatrix(view); err != nil {
+			t.Fatalf("DCTMatrix(%v) returned error %v", tt, err)
+		}
+		for y := 0; y < image.Rows; y++ {
+			for x := 0; x < image.Cols; x++ {
+				inside := y >= tt.i && y < tt.i+tt.rows && x >= tt.j && x < tt.j+tt.cols
+				got := image.At(y, x)
+				if !inside && got != orig[y*image.Stride+x] {
+					t.Fatalf("DCTMatrix(%v) changed [%d][%d] outside the view.", tt, y, x)
+				}
+				if inside && math.Abs(got-expect[(y-tt.i)*tt.cols+x-tt.j]) > 1e-6 {
+					t.Fatalf("DCTMatrix(%v)[%d][%d] expected %v but got %v.", tt, y-tt.i, x-tt.j, expect[(y-tt.i)*tt.cols+x-tt.j], got)
+				}
+			}
+		}
+
+		if err := NormalizeMatrix(view, NormOrtho); err != nil {
+			t.Fatalf("NormalizeMatrix(%v) returned error %v", tt, err)
+		}
+		if err := DenormalizeMatrix(view, NormOrtho); err != nil {
+			t.Fatalf("DenormalizeMatrix(%v) returned error %v", tt, err)
+		}
+		if err := IDCTMatrix(view); err != nil {
+			t.Fatalf("IDCTMatrix(%v) returned error %v", tt, err)
+		}
+		for k := range orig {
+			if math.Abs(image.Data[k]-orig[k]) > 1e-9 {
+				t.Fatalf("IDCTMatrix(DCTMatrix(%v)) expected %v at %d but got %v.", tt, orig[k], k, image.Data[k])
+			}
+		}
+	}
+
+	// blocks of a window match BlockDCT2D of the window copied out
+	view := image.Sub(8, 8, 16, 24)
+	plane := make([]float64, 0, 16*24)
+	for y := 0; y < view.Rows; y++ {
+		plane = append(plane, view.Row(y)...)
+	}
+	if err := BlockDCT2D(plane, 24, 16, 8); err != nil {
+		t.Fatalf("BlockDCT2D returned error %v", err)
+	}
+	if err := BlockDCTMatrix(view, 8); err != nil {
+		t.Fatalf("BlockDCTMatrix returned error %v", err)
+	}
+	for y := 0; y < view.Rows; y++ {
+		if !slices.Equal(view.Row(y), plane[y*24:(y+1)*24]) {
+			t.Fatalf("BlockDCTMatrix row %d is %v but BlockDCT2D gives %v.", y, view.Row(y), plane[y*24:(y+1)*24])
+		}
+	}
+
+	if _, err := MatrixOf([][]float64{{1, 2}, {3}}); err != ErrInvalidSize {
+		t.Errorf("MatrixOf of ragged rows expected error %v but got %v.", ErrInvalidSize, err)
+	}
+	if err := DCTMatrix(Matrix{Data: make([]float64, 10), Rows: 3, Cols: 4, Stride: 4}); err != ErrInvalidInput {
+		t.Errorf("DCTMatrix of a short Data expected error %v but got %v.", ErrInvalidInput, err)
+	}
+}
+
+func TestVariantMatrix(t *testing.T) {
+	r := rand.New(rand.NewSource(36))
+	image := NewMatrix(20, 24)
+	for i := range image.Data {
+		image.Data[i] = r.Float64() * 255
+	}
+
+	for _, tt := range []struct {
+		name      string
+		matrix    func(Matrix) error
+		transform func([]float64, int) ([]float64, error)
+	}{
+		{"DCTI", DCTIMatrix, DCTI_1D},
+		{"DCTIV", DCTIVMatrix, DCTIV_1D},
+		{"DSTI", DSTIMatrix, DSTI_1D},
+		{"DSTII", DSTIIMatrix, DSTII_1D},
+		{"DSTIII", DSTIIIMatrix, DSTIII_1D},
+		{"DSTIV", DSTIVMatrix, DSTIV_1D},
+	} {
+		for _, v := range []struct {
+			i, j, rows, cols int
+		}{
+			{2, 3, 9, 8},
+			{5, 1, 6, 15},
+			{19, 4, 1, 17},
+		} {
+			orig := slices.Clone(image.Data)
+			view := image.Sub(v.i, v.j, v.rows, v.cols)
+
+			// the rows, then the columns, of the window copied out
+			expect := NewMatrix(v.rows, v.cols)
+			for y := 0; y < v.rows; y++ {
+				row, _ := tt.transform(view.Row(y), v.cols)
+				copy(expect.Row(y), row)
+			}
+			for x := 0; x < v.cols && v.rows > 1; x++ {
+				col := make([]float64, v.rows)
+				for y := range col {
+					col[y] = expect.At(y, x)
+				}
+				col, _ = tt.transform(col, v.rows)
+				for y := range col {
+					expect.Set(y, x, col[y])
+				}
+			}
+
+			if err := tt.matrix(view); err != nil {
+				t.Fatalf("%sMatrix(%v) returned error %v", tt.name, v, err)
+			}
+			for y := 0; y < image.Rows; y++ {
+				for x := 0; x < image.Cols; x++ {
+					inside := y >= v.i && y < v.i+v.rows && x >= v.j && x < v.j+v.cols
+					got := image.At(y, x)
+					if !inside && got != orig[y*image.Stride+x] {
+						t.Fatalf("%sMatrix(%v) changed [%d][%d] outside the view.", tt.name, v, y, x)
+					}
+					if inside && math.Abs(got-expect.At(y-v.i, x-v.j)) > 1e-6 {
+						t.Fatalf("%sMatrix(%v)[%d][%d] expected %v but got %v.", tt.name, v, y-v.i, x-v.j, expect.At(y-v.i, x-v.j), got)
+					}
+				}
+			}
+			copy(image.Data, orig)
+		}
+	}
+
+	if err := DCTIMatrix(image.Sub(0, 0, 4, 1)); err != ErrInvalidInput {
+		t.Errorf("DCTIMatrix of a single column expected error %v but got %v.", ErrInvalidInput, err)
+	}
+	if err := DSTIIMatrix(Matrix{Data: make([]float64, 10), Rows: 3, Cols: 4, Stride: 4}); err != ErrInvalidInput {
+		t.Errorf("DSTIIMatrix of a short Data expected error %v but got %v.", ErrInvalidInput, err)
+	}
+}
+
+func TestMatrixF32(t *testing.T) {
+	r := rand.New(rand.NewSource(36))
+	image := NewMatrixF32(30, 40)
+	for i := range image.Data {
+		image.Data[i] = float32(r.Float64() * 255)
+	}
+
+	for _, v := range []struct {
+		i, j, rows, cols int
+	}{
+		{3, 5, 8, 8},
+		{1, 2, 16, 32},
+		{7, 2, 11, 5},
+		{29, 10, 1, 16},
+	} {
+		orig := slices.Clone(image.Data)
+		view := image.Sub(v.i, v.j, v.rows, v.cols)
+		window := NewMatrix(v.rows, v.cols)
+		for y := 0; y < v.rows; y++ {
+			for x, f := range view.Row(y) {
+				window.Set(y, x, float64(f))
+			}
+		}
+		if err := DCTMatrix(window); err != nil {
+			t.Fatalf("DCTMatrix(%v) returned error %v", v, err)
+		}
+
+		if err := DCTMatrixF32(view); err != nil {
+			t.Fatalf("DCTMatrixF32(%v) returned error %v", v, err)
+		}
+		for y := 0; y < v.rows; y++ {
+			for x, got := range view.Row(y) {
+				if expect := window.At(y, x); math.Abs(float64(got)-expect) > 1e-6*math.Max(1e4, math.Abs(expect)) {
+					t.Fatalf("DCTMatrixF32(%v)[%d][%d] expected %v but got %v.", v, y, x, expect, got)
+				}
+			}
+		}
+
+		if err := IDCTMatrixF32(view); err != nil {
+			t.Fatalf("IDCTMatrixF32(%v) returned error %v", v, err)
+		}
+		for k := range orig {
+			if math.Abs(float64(image.Data[k]-orig[k])) > 1e-3 {
+				t.Fatalf("IDCTMatrixF32(DCTMatrixF32(%v)) expected %v at %d but got %v.", v, orig[k], k, image.Data[k])
+			}
+		}
+		copy(image.Data, orig)
+	}
+
+	// a contiguous 8x8 takes the AAN kernel, as DCT_2D_F32 does
+	block := NewMatrixF32(8, 8)
+	copy(block.Data, image.Data)
+	expect := DCT_2D_F32(block.Data, 8)
+	if err := DCTMatrixF32(block); err != nil || !slices.Equal(block.Data, expect) {
+		t.Errorf("DCTMatrixF32 of 8x8 is %v, %v, expected %v.", block.Data, err, expect)
+	}
+
+	if err := DCTMatrixF32(MatrixF32{Data: make([]float32, 10), Rows: 3, Cols: 4, Stride: 4}); err != ErrInvalidInput {
+		t.Errorf("DCTMatrixF32 of a short Data expected error %v but got %v.", ErrInvalidInput, err)
+	}
+}
+
+func TestIntDCT(t *testing.T) {
+	r := rand.New(rand.NewSource(37))
+	for _, n := range []int{2, 4, 8, 16, 32, 64} {
+		for pattern := 0; pattern < 4; pattern++ {
+			input := make([]uint8, n*n)
+			for i := range input {
+				switch pattern {
+				case 0:
+					input[i] = uint8(r.Intn(256))
+				case 1: // gradient
+					input[i] = uint8(255 * (i/n + i%n) / (2 * n))
+				case 2: // checkerboard, the largest high frequencies
+					input[i] = uint8(255 * ((i/n + i%n) & 1))
+				case 3:
+					input[i] = 255
+				}
+			}
+			float_input := make([]float64, n*n)
+			for i, v := range input {
+				float_input[i] = float64(v)
+			}
+
+			out, err := DCT2DUint8(input, n)
+			if err != nil {
+				t.Fatalf("DCT2DUint8(%d) returned error %v", n, err)
+			}
+			expect := DCT_2D(float_input, n)
+			for i := range out {
+				if math.Abs(float64(out[i])-expect[i]) > IntDCTErrorBound {
+					t.Fatalf("DCT2DUint8(%d, pattern %d)[%d] expected %v but got %v.", n, pattern, i, expect[i], out[i])
+				}
+			}
+
+			// 12 bit samples, the error apart from rounding scales with them
+			input32 := make([]int32, n*n)
+			for i, v := range input {
+				input32[i] = (int32(v) - 128) * 31
+				float_input[i] = float64(input32[i])
+			}
+			out, err = DCT2DInt32(input32, n)
+			if err != nil {
+				t.Fatalf("DCT2DInt32(%d) returned error %v", n, err)
+			}
+			expect = DCT_2D(float_input, n)
+			for i := range out {
+				if math.Abs(float64(out[i])-expect[i]) > 0.5+(IntDCTErrorBound-0.5)*31 {
+					t.Fatalf("DCT2DInt32(%d, pattern %d)[%d] expected %v but got %v.", n, pattern, i, expect[i], out[i])
+				}
+			}
+		}
+	}
+
+	// the bits of a 32x32 pHash agree wherever the float coefficient is
+	// further than the bound from the threshold
+	for round := 0; round < 100; round++ {
+		input := make([]uint8, 32*32)
+		float_input := make([]float64, 32*32)
+		base := r.Intn(200)
+		for i := range input {
+			input[i] = uint8(base + r.Intn(56))
+			float_input[i] = float64(input[i])
+		}
+		out, _ := DCT2DUint8(input, 32)
+		expect := DCT_2D(float_input, 32)
+
+		corner := make([]float64, 0, 64)
+		for y := 0; y < 8; y++ {
+			corner = append(corner, expect[y*32:y*32+8]...)
+		}
+		median := slices.Clone(corner)
+		slices.Sort(median)
+		threshold := (median[31] + median[32]) / 2
+
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				c, c_int := expect[y*32+x], float64(out[y*32+x])
+				if math.Abs(c-threshold) > IntDCTErrorBound && (c > threshold) != (c_int > threshold) {
+					t.Fatalf("hash bit [%d][%d] differs: float %v, int %v, median %v.", y, x, c, c_int, threshold)
+				}
+				if math.Abs(c) > IntDCTErrorBound && (c > 0) != (c_int > 0) {
+					t.Fatalf("sign of [%d][%d] differs: float %v, int %v.", y, x, c, c_int)
+				}
+			}
+		}
+	}
+
+	for _, n := range []int{0, 1, 3, 128} {
+		if _, err := DCT2DUint8(make([]uint8, n*n), n); !errors.Is(err, ErrIntDCTSize) || !strings.HasPrefix(err.Error(), "DCT2DUint8: ") {
+			t.Errorf("DCT2DUint8(%d) expected error %v but got %v.", n, ErrIntDCTSize, err)
+		}
+	}
+	var size_err *SizeError
+	if _, err := DCT2DUint8(make([]uint8, 63), 8); !errors.As(err, &size_err) || size_err.Func != "DCT2DUint8" {
+		t.Errorf("DCT2DUint8 of 63 values expected a SizeError from DCT2DUint8 but got %v.", err)
+	}
+	if _, err := DCT2DInt32(make([]int32, 15), 4); !errors.As(err, &size_err) || size_err.Func != "DCT2DInt32" {
+		t.Errorf("DCT2DInt32 of 15 values expected a SizeError from DCT2DInt32 but got %v.", err)
+	}
+	if _, err := DCT2DInt32([]int32{0, 0, 0, MaxIntDCTInput}, 2); !errors.Is(err, ErrIntDCTRange) {
+		t.Errorf("DCT2DInt32 of %d expected error %v but got %v.", MaxIntDCTInput, ErrIntDCTRange, err)
+	}
+}
+
+func TestPadBlocks(t *testing.T) {
+	plane := []float64{
+		1, 2, 3,
+		4, 5, 6,
+	}
+	padded, w, h, err := PadBlocks(plane, 3, 2, 4)
+	if err != nil {
+		t.Fatalf("PadBlocks returned error %v", err)
+	}
+	expect := []float64{
+		1, 2, 3, 3,
+		4, 5, 6, 6,
+		4, 5, 6, 6,
+		4, 5, 6, 6,
+	}
+	if w != 4 || h != 4 || !slices.Equal(padded, expect) {
+		t.Errorf("PadBlocks(3, 2, 4) expected 4x4 %v but got %dx%d %v.", expect, w, h, padded)
+	}
+
+	for _, tt := range []struct {
+		width  int
+		height int
+		n      int
+		err    error
+	}{
+		{3, 2, 0, ErrInvalidSize},
+		{3, 2, -4, ErrInvalidSize},
+		{0, 2, 4, ErrInvalidInput},
+		{3, -2, 4, ErrInvalidInput},
+		{3, 3, 4, ErrInvalidInput},
+	} {
+		if _, _, _, err := PadBlocks(plane, tt.width, tt.height, tt.n); err != tt.err {
+			t.Errorf("PadBlocks(%d, %d, %d) expected error %v but got %v.", tt.width, tt.height, tt.n, tt.err, err)
+		}
+	}
+}
+
+func TestDCT2DFastE(t *testing.T) {
+	for _, tt := range []struct {
+		n    int
+		fast func([]float64) ([]float64, error)
+	}{
+		{8, func(in []float64) ([]float64, error) { out, err := DCT2DFast8E(in); return out[:], err }},
+		{16, func(in []float64) ([]float64, error) { out, err := DCT2DFast16E(in); return out[:], err }},
+		{32, func(in []float64) ([]float64, error) { out, err := DCT2DFast32E(in); return out[:], err }},
+		{64, func(in []float64) ([]float64, error) { out, err := DCT2DFast64E(in); return out[:], err }},
+		{128, func(in []float64) ([]float64, error) { out, err := DCT2DFast128E(in); return out[:], err }},
+		{256, func(in []float64) ([]float64, error) { out, err := DCT2DFast256E(in); return out[:], err }},
+	} {
+		out, err := tt.fast(flatten(ary2d[tt.n]))
+		if err != nil {
+			t.Fatalf("DCT2DFast%dE returned error %v", tt.n, err)
+		}
+		for i := 0; i < tt.n; i++ {
+			for j := 0; j < tt.n; j++ {
+				if math.Abs(out[i*tt.n+j]-exp2d[tt.n][i][j]) > EPSILON {
+					t.Fatalf("DCT2DFast%dE\n\texpected %v\n\n\tbut got %v.", tt.n, exp2d[tt.n], out)
+				}
+			}
+		}
+
+		if _, err := tt.fast(nil); err != ErrInvalidInput {
+			t.Errorf("DCT2DFast%dE(nil) expected error %v but got %v.", tt.n, ErrInvalidInput, err)
+		}
+
+		_, err = tt.fast(make([]float64, tt.n*tt.n+1))
+		var size_err *SizeError
+		if !errors.Is(err, ErrInvalidSize) || !errors.As(err, &size_err) || size_err.Size != tt.n || size_err.Len != tt.n*tt.n+1 {
+			t.Errorf("DCT2DFast%dE of %d values expected a SizeError but got %v.", tt.n, tt.n*tt.n+1, err)
+		}
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("DCT2DFast8 of 63 values expected a panic with ErrInvalidSize.")
+		}
+	}()
+	DCT2DFast8(make([]float64, 63))
+}
+
+func TestStaticTables(t *testing.T) {
+	for _, tt := range []struct {
+		table   []float64
+		table32 []float32
+	}{
+		{dct2[:], dct2F32[:]},
+		{dct4[:], dct4F32[:]},
+		{dct8[:], dct8F32[:]},
+		{dct16[:], dct16F32[:]},
+		{dct32[:], dct32F32[:]},
+		{dct64[:], dct64F32[:]},
+		{dct128[:], dct128F32[:]},
+		{dct256[:], dct256F32[:]},
+		{dct512[:], dct512F32[:]},
+		{dct1024[:], dct1024F32[:]},
+	} {
+		n := len(tt.table) * 2
+		coef := make([]float64, n)
+		fast_dct_coef(n, coef)
+		coef32 := make([]float32, n)
+		fast_dct_coef(n, coef32)
+
+		for i := range tt.table {
+			// The tables are generated at GOAMD64=v1. From v3 the compiler
+			// fuses the multiply-adds in math.Cos, which moves dct512[152]
+			// by an ulp, so an ulp is allowed where the tables were first
+			// checked exactly.
+			if tt.table[i] != coef[n/2+i] && math.Nextafter(coef[n/2+i], tt.table[i]) != tt.table[i] {
+				t.Errorf("dct%d[%d] = %v but fast_dct_coef gives %v.", n, i, tt.table[i], coef[n/2+i])
+			}
+			if tt.table32[i] != coef32[n/2+i] && math.Nextafter32(coef32[n/2+i], tt.table32[i]) != tt.table32[i] {
+				t.Errorf("dct%dF32[%d] = %v but fast_dct_coef gives %v.", n, i, tt.table32[i], coef32[n/2+i])
+			}
+		}
+	}
+
+	r := rand.New(rand.NewSource(1024))
+	for _, n := range []int{512, 1024} {
+		input := make([]float64, n)
+		for i := range input {
+			input[i] = r.Float64()
+		}
+		expect := naive_dct1d(input)
+		out := slices.Clone(input)
+		static_dct_kernel[float64](n)(out)
+		for i := range out {
+			if math.Abs(out[i]-expect[i]) > 1e-9 {
+				t.Fatalf("transformDCT%d[%d] expected %v but got %v.", n, i, expect[i], out[i])
+			}
+		}
+
+		input32 := make([]float32, n)
+		for i, v := range input {
+			input32[i] = float32(v)
+		}
+		static_dct_kernel[float32](n)(input32)
+		for i := range input32 {
+			if math.Abs(float64(input32[i])-expect[i]) > 1e-4*float64(n) {
+				t.Fatalf("transformDCT%dF32[%d] expected %v but got %v.", n, i, expect[i], input32[i])
+			}
+		}
+	}
+}
+
+// TestAsmKernels runs the 8x8 and 32x32 transforms with and without the
+// assembly kernels, which must agree to the bit.
+func TestAsmKernels(t *testing.T) {
+	if !hasAVX2 {
+		t.Skip("no assembly kernels on this CPU or build")
+	}
+	defer func() { useAVX2 = hasAVX2 }()
+
+	r := rand.New(rand.NewSource(34))
+	for round := 0; round < 20; round++ {
+		in8 := make([]float64, 64)
+		in32 := make([]float64, 32*32)
+		for i := range in8 {
+			in8[i] = r.Float64()*512 - 256
+		}
+		for i := range in32 {
+			in32[i] = r.Float64()*512 - 256
+		}
+		norm := Norm(round % 3)
+
+		var out8, out32, norm8 [2][]float64
+		var rows32 [2][]float64
+		for k, use := range []bool{false, true} {
+			useAVX2 = use
+			out8[k] = DCT_2D(in8, 8)
+			norm8[k] = DCT_2D_Norm(in8, 8, norm)
+			rows32[k] = slices.Clone(in32)
+			flat, _ := DCT2DFast32E(rows32[k])
+			out32[k] = flat[:]
+		}
+
+		for _, tt := range []struct {
+			name     string
+			go_, asm []float64
+		}{
+			{"DCT_2D(8)", out8[0], out8[1]},
+			{"DCT_2D_Norm(8)", norm8[0], norm8[1]},
+			{"DCT2DFast32E", out32[0], out32[1]},
+			{"DCT2DFast32E input", rows32[0], rows32[1]},
+		} {
+			for i := range tt.go_ {
+				if math.Float64bits(tt.go_[i]) != math.Float64bits(tt.asm[i]) {
+					t.Fatalf("%s[%d] is %v in Go but %v in assembly.", tt.name, i, tt.go_[i], tt.asm[i])
+				}
+			}
+		}
+	}
+}
+
 func init() {
 	createTestData()
 }
@@ -427,6 +1470,33 @@ func naive_dct2d(vector [][]float64) [][]float64 {
 	return result
 }
 
+// naive_dct_mxn is the separable DCT-II of a rows x cols matrix
+func naive_dct_mxn(vector [][]float64) [][]float64 {
+	rows := len(vector)
+	cols := len(vector[0])
+	temp := make([][]float64, rows)
+	for x := 0; x < rows; x++ {
+		temp[x] = naive_dct1d(vector[x])
+	}
+
+	result := make([][]float64, rows)
+	for y := 0; y < rows; y++ {
+		result[y] = make([]float64, cols)
+	}
+
+	col := make([]float64, rows)
+	for y := 0; y < cols; y++ {
+		for x := 0; x < rows; x++ {
+			col[x] = temp[x][y]
+		}
+		out := naive_dct1d(col)
+		for x := 0; x < rows; x++ {
+			result[x][y] = out[x]
+		}
+	}
+	return result
+}
+
 var dct []float64
 
 func BenchmarkDCT_2D_8(b *testing.B) {
@@ -435,6 +1505,14 @@ func BenchmarkDCT_2D_8(b *testing.B) {
 	}
 }
 
+func BenchmarkDCT_2D_8_Go(b *testing.B) {
+	useAVX2 = false
+	defer func() { useAVX2 = hasAVX2 }()
+	for i := 0; i < b.N; i++ {
+		dct = DCT_2D(ary2d_flat[8], 8)
+	}
+}
+
 func BenchmarkDCT_2D_16(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		dct = DCT_2D(ary2d_flat[16], 16)
@@ -483,6 +1561,14 @@ func BenchmarkDCT2DFast32(b *testing.B) {
 	}
 }
 
+func BenchmarkDCT2DFast32_Go(b *testing.B) {
+	useAVX2 = false
+	defer func() { useAVX2 = hasAVX2 }()
+	for i := 0; i < b.N; i++ {
+		_ = DCT2DFast32(ary2d_flat[32])
+	}
+}
+
 func BenchmarkDCT2DFast64(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = DCT2DFast64(ary2d_flat[64])
@@ -500,3 +1586,257 @@ func BenchmarkDCT2DFast256(b *testing.B) {
 		_ = DCT2DFast256(ary2d_flat[256])
 	}
 }
+
+func BenchmarkPartialDCT2D_32_8(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct, _ = PartialDCT2D(ary2d_flat[32], 32, 8)
+	}
+}
+
+func BenchmarkDCT_2D_32_Slice8(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct = sliceCorner(DCT_2D(ary2d_flat[32], 32), 32, 8)
+	}
+}
+
+func BenchmarkPartialDCT2D_64_16(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct, _ = PartialDCT2D(ary2d_flat[64], 64, 16)
+	}
+}
+
+func BenchmarkDCT_2D_64_Slice16(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct = sliceCorner(DCT_2D(ary2d_flat[64], 64), 64, 16)
+	}
+}
+
+func BenchmarkPartialDCT2D_256_16(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct, _ = PartialDCT2D(ary2d_flat[256], 256, 16)
+	}
+}
+
+func BenchmarkDCT_2D_256_Slice16(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct = sliceCorner(DCT_2D(ary2d_flat[256], 256), 256, 16)
+	}
+}
+
+func BenchmarkPartialDCT2D_11_5(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct, _ = PartialDCT2D(ary2d_flat[11], 11, 5)
+	}
+}
+
+func BenchmarkDCT_2D_11_Slice5(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct = sliceCorner(DCT_2D(ary2d_flat[11], 11), 11, 5)
+	}
+}
+
+// sliceCorner copies the top left kxk block out of a flattened nxn matrix
+func sliceCorner(in []float64, n, k int) []float64 {
+	out := make([]float64, 0, k*k)
+	for i := 0; i < k; i++ {
+		out = append(out, in[i*n:i*n+k]...)
+	}
+	return out
+}
+
+func BenchmarkDCT_MxN_32x64(b *testing.B) {
+	in := append(slices.Clone(ary2d_flat[32]), ary2d_flat[32]...)
+	for i := 0; i < b.N; i++ {
+		dct, _ = DCT_MxN(in, 32, 64)
+	}
+}
+
+func BenchmarkIDCT_2D_32(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct = IDCT_2D(ary2d_flat[32], 32)
+	}
+}
+
+func BenchmarkDCT_2D_F32_32(b *testing.B) {
+	in := make([]float32, 32*32)
+	for i, v := range ary2d_flat[32] {
+		in[i] = float32(v)
+	}
+	for i := 0; i < b.N; i++ {
+		_ = DCT_2D_F32(in, 32)
+	}
+}
+
+func BenchmarkDCT_2D_100(b *testing.B) {
+	input := make([]float64, 100*100)
+	for i := 0; i < b.N; i++ {
+		_ = DCT_2D(input, 100)
+	}
+}
+
+func BenchmarkDCT_2D_100_Naive(b *testing.B) {
+	input := make([]float64, 100*100)
+	for i := 0; i < b.N; i++ {
+		dct_2d(input, 100)
+	}
+}
+
+func BenchmarkDCT_1D_1000(b *testing.B) {
+	input := make([]float64, 1000)
+	for i := 0; i < b.N; i++ {
+		_ = DCT_1D(input, 1000)
+	}
+}
+
+func BenchmarkDCT_1D_1000_Naive(b *testing.B) {
+	input := make([]float64, 1000)
+	for i := 0; i < b.N; i++ {
+		dct_1d(input, 1000)
+	}
+}
+
+func BenchmarkDCTIV_1D_256(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct, _ = DCTIV_1D(ary[256], 256)
+	}
+}
+
+func BenchmarkDCTIV_1D_Naive_256(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		dct = slices.Clone(ary[256])
+		dct4_1d(dct, 256)
+	}
+}
+
+func BenchmarkBlockDCT2D_512_8(b *testing.B) {
+	plane := make([]float64, 512*512)
+	for i := range plane {
+		plane[i] = ary2d_flat[256][i%len(ary2d_flat[256])]
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = BlockDCT2D(plane, 512, 512, 8)
+	}
+}
+
+func BenchmarkDCT2DUint8_32(b *testing.B) {
+	input := make([]uint8, 32*32)
+	for i, v := range ary2d_flat[32] {
+		input[i] = uint8(v * 255)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = DCT2DUint8(input, 32)
+	}
+}
+
+// BenchmarkDCT_2D_Uint8_32 is the float path for the same 8 bit input.
+func BenchmarkDCT_2D_Uint8_32(b *testing.B) {
+	input := make([]uint8, 32*32)
+	for i, v := range ary2d_flat[32] {
+		input[i] = uint8(v * 255)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		float_input := make([]float64, 32*32)
+		for j, v := range input {
+			float_input[j] = float64(v)
+		}
+		_ = DCT_2D(float_input, 32)
+	}
+}
+
+// fft_crossover_sizes are sizes that are not powers of 2 around
+// fft_threshold, prime and composite, for the crossover benchmarks.
+var fft_crossover_sizes = []int{5, 6, 7, 11, 12, 13, 17, 24, 31, 37, 47, 48, 61, 100}
+
+// BenchmarkFFTCrossover1D compares, for one transform as DCT_1D runs it, the
+// O(n^2) dct_1d with the FFT kernel, including its plan.
+func BenchmarkFFTCrossover1D(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range fft_crossover_sizes {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = r.Float64()
+		}
+		buf := make([]float64, n)
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, x)
+				dct_1d(buf, n)
+			}
+		})
+		b.Run(fmt.Sprintf("fft/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, x)
+				fft_dct_kernel[float64](n, false)(buf)
+			}
+		})
+	}
+}
+
+// BenchmarkFFTCrossover2D compares, as DCT_2D runs them, dct_2d with the
+// separable transform by the FFT kernel.
+func BenchmarkFFTCrossover2D(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range fft_crossover_sizes {
+		x := make([]float64, n*n)
+		for i := range x {
+			x[i] = r.Float64()
+		}
+		buf := make([]float64, n*n)
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, x)
+				dct_2d(buf, n)
+			}
+		})
+		b.Run(fmt.Sprintf("fft/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, x)
+				kernel := fft_dct_kernel[float64](n, false)
+				separable_2d(buf, n, n, kernel, kernel)
+			}
+		})
+	}
+}
+
+// BenchmarkFFTCrossoverKernel compares the kernels dct_1d_kernel chooses
+// between, built once and reused as the Matrix and block transforms do: the
+// coefficient table product and the FFT.
+func BenchmarkFFTCrossoverKernel(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range fft_crossover_sizes {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = r.Float64()
+		}
+		buf := make([]float64, n)
+		coef := make([][]float64, n)
+		for i := range coef {
+			coef[i] = make([]float64, n)
+		}
+		dct_coef(n, coef)
+		temp := make([]float64, n)
+		b.Run(fmt.Sprintf("table/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, x)
+				for k := 0; k < n; k++ {
+					var sum float64
+					for j := 0; j < n; j++ {
+						sum += buf[j] * coef[j][k]
+					}
+					temp[k] = sum
+				}
+				copy(buf, temp)
+			}
+		})
+		kernel := fft_dct_kernel[float64](n, false)
+		b.Run(fmt.Sprintf("fft/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, x)
+				kernel(buf)
+			}
+		})
+	}
+}
@@ -4,7 +4,25 @@ import (
 	"math"
 )
 
-func fct8_1d( /*inbuf []byte*/ vector []float64) {
+func fct8_1d[T Float]( /*inbuf []byte*/ vector []T) {
+	fct8_1d_scaled(vector, &fct8_scale)
+}
+
+// AAN output scaling giving the unscaled DCT-II
+var fct8_scale = [8]float64{
+	1,
+	0.509795579104157595,
+	0.54119610014619577,
+	0.60134488693504412,
+	0.707106781186547,
+	0.8999762231364133,
+	1.30656296487637502,
+	2.5629154477415022505,
+}
+
+// fct8_1d_scaled is fct8_1d with the output scaling taken from scale, so a
+// normalisation can be folded into the AAN factors at no extra cost.
+func fct8_1d_scaled[T Float](vector []T, scale *[8]float64) {
 	v0 := vector[0] + vector[7]
 	v1 := vector[1] + vector[6]
 	v2 := vector[2] + vector[5]
@@ -18,17 +36,20 @@ func fct8_1d( /*inbuf []byte*/ vector []float64) {
 	v9 := v1 + v2
 	v10 := v1 - v2
 	v11 := v0 - v3
+	// The products are rounded with T() so they are never fused into
+	// FMAs, keeping the output the same on every GOAMD64 level and
+	// bit-identical to the assembly kernels.
 	v12 := -v4 - v5
-	v13 := (v5 + v6) * 0.707106781186547524400844
+	v13 := T((v5 + v6) * 0.707106781186547524400844)
 	v14 := v6 + v7
 
 	v15 := v8 + v9
 	v16 := v8 - v9
-	v17 := (v10 + v11) * 0.707106781186547524400844
-	v18 := (v12 + v14) * 0.382683432365089771728460
+	v17 := T((v10 + v11) * 0.707106781186547524400844)
+	v18 := T((v12 + v14) * 0.382683432365089771728460)
 
-	v19 := -v12*0.541196100146196984399723 - v18
-	v20 := v14*1.306562964876376527856643 - v18
+	v19 := T(-v12*0.541196100146196984399723) - v18
+	v20 := T(v14*1.306562964876376527856643) - v18
 
 	v21 := v17 + v11
 	v22 := v11 - v17
@@ -40,21 +61,29 @@ func fct8_1d( /*inbuf []byte*/ vector []float64) {
 	v27 := v23 - v20
 	v28 := v24 - v19
 
-	vector[0] = v15
-	vector[1] = 0.509795579104157595 * v26
-	vector[2] = 0.54119610014619577 * v21
-	vector[3] = 0.60134488693504412 * v28
-	vector[4] = 0.707106781186547 * v16
-	vector[5] = 0.8999762231364133 * v25
-	vector[6] = 1.30656296487637502 * v22
-	vector[7] = 2.5629154477415022505 * v27
+	vector[0] = T(scale[0]) * v15
+	vector[1] = T(scale[1]) * v26
+	vector[2] = T(scale[2]) * v21
+	vector[3] = T(scale[3]) * v28
+	vector[4] = T(scale[4]) * v16
+	vector[5] = T(scale[5]) * v25
+	vector[6] = T(scale[6]) * v22
+	vector[7] = T(scale[7]) * v27
 }
 
-func fct8_2d(inbuf []float64) {
-	temp := make([]float64, 64)
+func fct8_2d[T Float](inbuf []T) {
+	fct8_2d_scaled(inbuf, &fct8_scale)
+}
+
+func fct8_2d_scaled[T Float](inbuf []T, scale *[8]float64) {
+	if buf, ok := any(inbuf).([]float64); ok && fct8_2d_asm(buf, scale) {
+		return
+	}
+
+	temp := make([]T, 64)
 
 	for x := 0; x < 64; x += 8 {
-		fct8_1d(inbuf[x : x+8])
+		fct8_1d_scaled(inbuf[x:x+8], scale)
 	}
 
 	for x := 0; x < 8; x++ {
@@ -64,7 +93,7 @@ func fct8_2d(inbuf []float64) {
 	}
 
 	for y := 0; y < 64; y += 8 {
-		fct8_1d(temp[y : y+8])
+		fct8_1d_scaled(temp[y:y+8], scale)
 	}
 
 	for x := 0; x < 8; x++ {
@@ -74,15 +103,15 @@ func fct8_2d(inbuf []float64) {
 	}
 }
 
-func dct_1d(inbuf []float64, size int) {
-	temp := make([]float64, size)
+func dct_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
 	factor := math.Pi / float64(size)
 
 	for i := 0; i < size; i++ {
-		sum := 0.0
+		var sum T
 		mult := float64(i) * factor
 		for j := 0; j < size; j++ {
-			sum += inbuf[j] * math.Cos((float64(j)+0.5)*mult)
+			sum += inbuf[j] * T(math.Cos((float64(j)+0.5)*mult))
 		}
 		temp[i] = sum
 	}
@@ -92,16 +121,16 @@ func dct_1d(inbuf []float64, size int) {
 	}
 }
 
-func idct_1d(inbuf []float64, size int) {
-	temp := make([]float64, size)
+func idct_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
 	factor := math.Pi / float64(size)
-	scale := 2.0 / float64(size)
+	scale := T(2.0 / float64(size))
 
 	for i := 0; i < size; i++ {
 		sum := inbuf[0] / 2.0
 		mult := (float64(i) + 0.5) * factor
 		for j := 1; j < size; j++ {
-			sum += inbuf[j] * math.Cos(float64(j)*mult)
+			sum += inbuf[j] * T(math.Cos(float64(j)*mult))
 		}
 		temp[i] = sum
 	}
@@ -111,40 +140,40 @@ func idct_1d(inbuf []float64, size int) {
 	}
 }
 
-func dct_coef(size int, coef [][]float64) {
+func dct_coef[T Float](size int, coef [][]T) {
 	factor := math.Pi / float64(size)
 
 	for i := 0; i < size; i++ {
 		mult := float64(i) * factor
 		for j := 0; j < size; j++ {
-			coef[j][i] = math.Cos((float64(j) + 0.5) * mult)
+			coef[j][i] = T(math.Cos((float64(j) + 0.5) * mult))
 		}
 	}
 }
 
-func idct_coef(size int, coef [][]float64) {
+func idct_coef[T Float](size int, coef [][]T) {
 	factor := math.Pi / float64(size)
 
 	for i := 0; i < size; i++ {
 		mult := (float64(i) + 0.5) * factor
 		for j := 0; j < size; j++ {
-			coef[j][i] = math.Cos(float64(j) * mult)
+			coef[j][i] = T(math.Cos(float64(j) * mult))
 		}
 	}
 }
 
-func dct_2d(inbuf []float64, size int) {
-	temp := make([]float64, size*size)
-	coef := make([][]float64, size)
+func dct_2d[T Float](inbuf []T, size int) {
+	temp := make([]T, size*size)
+	coef := make([][]T, size)
 	for i := range coef {
-		coef[i] = make([]float64, size)
+		coef[i] = make([]T, size)
 	}
 
 	dct_coef(size, coef)
 
 	for x := 0; x < size; x++ {
 		for i := 0; i < size; i++ {
-			sum := 0.0
+			var sum T
 			y := x * size
 			for j := 0; j < size; j++ {
 				sum += inbuf[y+j] * coef[j][i]
@@ -155,7 +184,7 @@ func dct_2d(inbuf []float64, size int) {
 
 	for y := 0; y < size; y++ {
 		for i := 0; i < size; i++ {
-			sum := 0.0
+			var sum T
 			for j := 0; j < size; j++ {
 				sum += temp[j*size+y] * coef[j][i]
 			}
@@ -164,13 +193,13 @@ func dct_2d(inbuf []float64, size int) {
 	}
 }
 
-func idct_2d(inbuf []float64, size int) {
-	coef := make([][]float64, size)
+func idct_2d[T Float](inbuf []T, size int) {
+	coef := make([][]T, size)
 	for i := range coef {
-		coef[i] = make([]float64, size)
+		coef[i] = make([]T, size)
 	}
-	temp := make([]float64, size*size)
-	scale := 2.0 / float64(size)
+	temp := make([]T, size*size)
+	scale := T(2.0 / float64(size))
 
 	idct_coef(size, coef)
 
@@ -196,32 +225,32 @@ func idct_2d(inbuf []float64, size int) {
 	}
 }
 
-func fast_dct_1d_precalc(inbuf []float64, size int, coef []float64) {
-	temp := make([]float64, size)
+func fast_dct_1d_precalc[T Float](inbuf []T, size int, coef []T) {
+	temp := make([]T, size)
 
 	transform_recursive(inbuf, temp, size, coef)
 }
 
-func fast_dct_coef(size int, coef []float64) {
+func fast_dct_coef[T Float](size int, coef []T) {
 	for i := 1; i <= size/2; i *= 2 {
 		factor := math.Pi / float64(i*2)
 		for j := 0; j < i; j++ {
-			coef[i+j] = math.Cos((float64(j)+0.5)*factor) * 2
+			coef[i+j] = T(math.Cos((float64(j)+0.5)*factor) * 2)
 		}
 	}
 }
 
-func fast_dct_1d(inbuf []float64, size int) {
-	coef := make([]float64, size)
+func fast_dct_1d[T Float](inbuf []T, size int) {
+	coef := make([]T, size)
 
 	fast_dct_coef(size, coef)
 
 	fast_dct_1d_precalc(inbuf, size, coef)
 }
 
-func fast_dct_2d(inbuf []float64, size int) {
-	coef := make([]float64, size)
-	temp := make([]float64, size*size)
+func fast_dct_2d[T Float](inbuf []T, size int) {
+	coef := make([]T, size)
+	temp := make([]T, size*size)
 
 	fast_dct_coef(size, coef)
 
@@ -250,7 +279,7 @@ func fast_dct_2d(inbuf []float64, size int) {
 	}
 }
 
-func transform_recursive(inbuf, temp []float64, size int, coef []float64) {
+func transform_recursive[T Float](inbuf, temp []T, size int, coef []T) {
 	if size == 1 {
 		return
 	}
@@ -277,3 +306,74 @@ func transform_recursive(inbuf, temp []float64, size int, coef []float64) {
 	inbuf[size-2] = temp[half-1]
 	inbuf[size-1] = temp[size-1]
 }
+
+func fast_idct_1d_precalc[T Float](inbuf []T, size int, coef []T) {
+	temp := make([]T, size)
+	scale := T(2.0 / float64(size))
+
+	inbuf[0] /= 2.0
+	inverse_recursive(inbuf, temp, size, coef)
+
+	for i := 0; i < size; i++ {
+		inbuf[i] *= scale
+	}
+}
+
+func fast_idct_1d[T Float](inbuf []T, size int) {
+	coef := make([]T, size)
+
+	fast_dct_coef(size, coef)
+
+	fast_idct_1d_precalc(inbuf, size, coef)
+}
+
+// inverse_recursive is the transpose of transform_recursive, an unscaled DCT-III.
+func inverse_recursive[T Float](inbuf, temp []T, size int, coef []T) {
+	if size == 1 {
+		return
+	}
+
+	half := size / 2
+
+	temp[0] = inbuf[0]
+	temp[half] = inbuf[1]
+	for i := 1; i < half; i++ {
+		temp[i] = inbuf[i*2]
+		temp[i+half] = inbuf[i*2-1] + inbuf[i*2+1]
+	}
+
+	inverse_recursive(temp, inbuf, half, coef)
+	inverse_recursive(temp[half:], inbuf, half, coef)
+
+	for i := 0; i < half; i++ {
+		x := temp[i]
+		y := temp[i+half] / coef[half+i]
+		inbuf[i] = x + y
+		inbuf[size-1-i] = x - y
+	}
+}
+
+// separable_2d applies row_kernel to each of the rows and col_kernel to each
+// of the cols of a flattened rows x cols matrix.
+func separable_2d[T Float](inbuf []T, rows, cols int, row_kernel, col_kernel func([]T)) {
+	separable_2d_strided(inbuf, rows, cols, cols, row_kernel, col_kernel)
+}
+
+// separable_2d_strided is separable_2d for a matrix whose rows start stride
+// elements apart.
+func separable_2d_strided[T Float](inbuf []T, rows, cols, stride int, row_kernel, col_kernel func([]T)) {
+	for y := 0; y < rows*stride; y += stride {
+		row_kernel(inbuf[y : y+cols])
+	}
+
+	temp := make([]T, rows)
+	for x := 0; x < cols; x++ {
+		for y := 0; y < rows; y++ {
+			temp[y] = inbuf[y*stride+x]
+		}
+		col_kernel(temp)
+		for y := 0; y < rows; y++ {
+			inbuf[y*stride+x] = temp[y]
+		}
+	}
+}
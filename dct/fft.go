@@ -0,0 +1,313 @@
+package dct
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// fft_threshold is the smallest size that is not a power of 2 for which the
+// kernels and the 2D transforms use the FFT based transforms instead of the
+// O(n^2) matrix product. Their products read a coefficient table, and
+// BenchmarkFFTCrossover2D and BenchmarkFFTCrossoverKernel put the crossover
+// between 47 and 61, later for primes.
+const fft_threshold = 48
+
+// fft_threshold_1d is the smallest size from which DCT_1D and IDCT_1D, which
+// plan for a single transform, take the FFT when n has no prime factor above
+// max_radix. Their O(n^2) loops evaluate a cosine per term, and
+// BenchmarkFFTCrossover1D has the FFT, plan included, ahead from 11. Sizes
+// needing Bluestein stay slower below fft_threshold.
+const fft_threshold_1d = 11
+
+// use_fft_1d reports whether DCT_1D and IDCT_1D transform n points, not a
+// power of 2, through an FFT.
+func use_fft_1d(n int) bool {
+	return n >= fft_threshold || (n >= fft_threshold_1d && small_factors(n) != nil)
+}
+
+// fft is an in place forward DFT of a fixed length,
+// X[k] = sum x[n] exp(-2 pi i n k / N).
+type fft interface {
+	transform(x []complex128)
+}
+
+// new_fft returns the fastest plan for n: radix-2 for powers of 2, mixed
+// radix when n only has small prime factors, and Bluestein otherwise.
+func new_fft(n int) fft {
+	if n&(n-1) == 0 {
+		return new_radix2(n)
+	}
+	if factors := small_factors(n); factors != nil {
+		return new_mixed_radix(n, factors)
+	}
+	return new_bluestein(n)
+}
+
+// max_radix is the largest prime factor mixed_radix handles, with an O(p^2)
+// butterfly; sizes with larger ones go to Bluestein.
+const max_radix = 13
+
+// small_factors returns the prime factors of n, 4s first, or nil if one of
+// them exceeds max_radix.
+func small_factors(n int) []int {
+	var factors []int
+	for n%4 == 0 {
+		factors = append(factors, 4)
+		n /= 4
+	}
+	for p := 2; p <= max_radix && n > 1; p++ {
+		for n%p == 0 {
+			factors = append(factors, p)
+			n /= p
+		}
+	}
+	if n > 1 {
+		return nil
+	}
+	return factors
+}
+
+// radix2 is the iterative Cooley-Tukey FFT for a power of 2 length.
+type radix2 struct {
+	n       int
+	twiddle []complex128 // exp(-2 pi i k / n) for k < n/2
+}
+
+func new_radix2(n int) *radix2 {
+	f := &radix2{n: n, twiddle: make([]complex128, n/2)}
+	for k := range f.twiddle {
+		f.twiddle[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+	}
+	return f
+}
+
+func (f *radix2) transform(x []complex128) {
+	n := f.n
+	if n <= 1 {
+		return
+	}
+
+	shift := uint(bits.UintSize - bits.Len(uint(n-1)))
+	for i := 0; i < n; i++ {
+		if j := int(bits.Reverse(uint(i)) >> shift); i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size *= 2 {
+		half, step := size/2, n/size
+		for start := 0; start < n; start += size {
+			for k := 0; k < half; k++ {
+				t := x[start+k+half] * f.twiddle[k*step]
+				x[start+k+half] = x[start+k] - t
+				x[start+k] += t
+			}
+		}
+	}
+}
+
+// mixed_radix is the recursive decimation in time Cooley-Tukey FFT, splitting
+// n into the factors in turn.
+type mixed_radix struct {
+	n       int
+	factors []int
+	twiddle []complex128 // exp(-2 pi i k / n) for k < n
+	scratch []complex128
+}
+
+func new_mixed_radix(n int, factors []int) *mixed_radix {
+	f := &mixed_radix{
+		n:       n,
+		factors: factors,
+		twiddle: make([]complex128, n),
+		scratch: make([]complex128, n),
+	}
+	for k := range f.twiddle {
+		f.twiddle[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+	}
+	return f
+}
+
+func (f *mixed_radix) transform(x []complex128) {
+	copy(f.scratch, x)
+	f.recursive(x, f.scratch, f.n, 1, f.factors)
+}
+
+// recursive writes the n point DFT of in[0], in[stride], ... to out.
+func (f *mixed_radix) recursive(out, in []complex128, n, stride int, factors []int) {
+	if n == 1 {
+		out[0] = in[0]
+		return
+	}
+
+	p, m := factors[0], n/factors[0]
+	for r := 0; r < p; r++ {
+		f.recursive(out[r*m:(r+1)*m], in[r*stride:], m, stride*p, factors[1:])
+	}
+
+	// X[k + m q] = sum_r W_n^(r k) Y_r[k] W_p^(r q), over the p outputs
+	// sharing k, which sit where the Y_r[k] were
+	var y [max_radix + 3]complex128
+	tw_step, p_step := f.n/n, f.n/p
+	for k := 0; k < m; k++ {
+		for r := 0; r < p; r++ {
+			y[r] = out[r*m+k] * f.twiddle[r*k*tw_step]
+		}
+		for q := 0; q < p; q++ {
+			sum, rq := y[0], 0 // rq = r q mod p
+			for r := 1; r < p; r++ {
+				if rq += q; rq >= p {
+					rq -= p
+				}
+				sum += y[r] * f.twiddle[rq*p_step]
+			}
+			out[k+m*q] = sum
+		}
+	}
+}
+
+// bluestein computes a DFT of any length n as a convolution with a chirp,
+// using n k = (n^2 + k^2 - (k-n)^2) / 2, done with radix-2 FFTs of a length
+// of at least 2n-1.
+type bluestein struct {
+	n     int
+	chirp []complex128 // exp(-pi i k^2 / n)
+	kern  []complex128 // FFT of the conjugate chirp, wrapped around
+	buf   []complex128
+	fft   *radix2
+}
+
+func new_bluestein(n int) *bluestein {
+	m := 1 << bits.Len(uint(2*n-2))
+	f := &bluestein{
+		n:     n,
+		chirp: make([]complex128, n),
+		kern:  make([]complex128, m),
+		buf:   make([]complex128, m),
+		fft:   new_radix2(m),
+	}
+
+	for k := 0; k < n; k++ {
+		// k^2 mod 2n keeps the angle small and accurate
+		f.chirp[k] = cmplx.Rect(1, -math.Pi*float64((k*k)%(2*n))/float64(n))
+	}
+
+	f.kern[0] = cmplx.Conj(f.chirp[0])
+	for k := 1; k < n; k++ {
+		f.kern[k] = cmplx.Conj(f.chirp[k])
+		f.kern[m-k] = f.kern[k]
+	}
+	f.fft.transform(f.kern)
+	return f
+}
+
+func (f *bluestein) transform(x []complex128) {
+	m := len(f.buf)
+	for k := 0; k < f.n; k++ {
+		f.buf[k] = x[k] * f.chirp[k]
+	}
+	clear(f.buf[f.n:])
+
+	f.fft.transform(f.buf)
+	for k := range f.buf {
+		f.buf[k] = cmplx.Conj(f.buf[k] * f.kern[k])
+	}
+	f.fft.transform(f.buf) // the inverse, by conjugation
+
+	scale := 1 / float64(m)
+	for k := 0; k < f.n; k++ {
+		f.buf[k] = cmplx.Conj(f.buf[k])
+		x[k] = f.buf[k] * f.chirp[k] * complex(scale, 0)
+	}
+}
+
+// fft_dct is Makhoul's DCT-II of any length through one complex FFT of the
+// same length: the even samples in order followed by the odd ones reversed,
+// transformed and rotated by exp(-pi i k / 2N).
+type fft_dct struct {
+	n       int
+	fft     fft
+	twiddle []complex128 // exp(-pi i k / 2n)
+	buf     []complex128
+}
+
+func new_fft_dct(n int) *fft_dct {
+	f := &fft_dct{
+		n:       n,
+		fft:     new_fft(n),
+		twiddle: make([]complex128, n),
+		buf:     make([]complex128, n),
+	}
+	for k := range f.twiddle {
+		f.twiddle[k] = cmplx.Rect(1, -math.Pi*float64(k)/float64(2*n))
+	}
+	return f
+}
+
+// dct is the unscaled DCT-II of dct_1d.
+func (f *fft_dct) dct(x []float64) {
+	n := f.n
+	for i := 0; 2*i < n; i++ {
+		f.buf[i] = complex(x[2*i], 0)
+	}
+	for i := 0; 2*i+1 < n; i++ {
+		f.buf[n-1-i] = complex(x[2*i+1], 0)
+	}
+
+	f.fft.transform(f.buf)
+
+	for k := 0; k < n; k++ {
+		x[k] = real(f.buf[k] * f.twiddle[k])
+	}
+}
+
+// idct is the inverse of dct, as idct_1d. For real samples
+// V[k] = exp(pi i k / 2N) (X[k] - i X[N-k]) with X[N] = 0, and the inverse
+// FFT is done as a forward one by conjugation.
+func (f *fft_dct) idct(x []float64) {
+	n := f.n
+	f.buf[0] = complex(x[0], 0)
+	for k := 1; k < n; k++ {
+		f.buf[k] = cmplx.Conj(complex(x[k], -x[n-k]) * cmplx.Conj(f.twiddle[k]))
+	}
+
+	f.fft.transform(f.buf)
+
+	scale := 1 / float64(n)
+	for i := 0; 2*i < n; i++ {
+		x[2*i] = real(f.buf[i]) * scale
+	}
+	for i := 0; 2*i+1 < n; i++ {
+		x[2*i+1] = real(f.buf[n-1-i]) * scale
+	}
+}
+
+// fft_dct_kernel returns an in place n point DCT-II, or its inverse, through
+// fft_dct. It keeps scratch space, so it must not be shared between
+// goroutines.
+func fft_dct_kernel[T Float](n int, inverse bool) func([]T) {
+	f := new_fft_dct(n)
+	if _, ok := any(T(0)).(float64); ok {
+		transform := f.dct
+		if inverse {
+			transform = f.idct
+		}
+		return any(transform).(func([]T))
+	}
+
+	temp := make([]float64, n)
+	return func(x []T) {
+		for i := range temp {
+			temp[i] = float64(x[i])
+		}
+		if inverse {
+			f.idct(temp)
+		} else {
+			f.dct(temp)
+		}
+		for i := range temp {
+			x[i] = T(temp[i])
+		}
+	}
+}
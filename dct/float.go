@@ -0,0 +1,7 @@
+package dct
+
+// Float is the set of element types the transforms are implemented for.
+// float32 halves memory bandwidth and is precise enough for hash sign bits.
+type Float interface {
+	float32 | float64
+}
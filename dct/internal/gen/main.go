@@ -0,0 +1,202 @@
+// Command gen writes the static DCT-II tables and unrolled Lee kernels of the
+// dct package for every power of 2 size from 2 and 4 respectively up to -max,
+// in float64 and float32. Run it with go generate from the dct package directory.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"text/template"
+)
+
+type variant struct {
+	File   string // output file
+	Type   string // element type
+	Suffix string // appended to kernel and table names
+	Bits   int    // precision of the table literals
+}
+
+type kernel struct {
+	N, Half int
+}
+
+type data struct {
+	variant
+	Kernels []kernel // sizes 16 and up, largest first
+	Sizes   []int    // all sizes with a static kernel
+	Tables  []table
+}
+
+type table struct {
+	N, Half int
+	Rows    [][]string
+}
+
+// coef is the Lee butterfly divisor 2 cos((i+0.5) pi / n), as fast_dct_coef
+// computes it at runtime.
+func coef(n, i int) float64 {
+	return math.Cos((float64(i)+0.5)*(math.Pi/float64(n))) * 2
+}
+
+func literal(v float64, bits int) string {
+	if bits == 32 {
+		return strconv.FormatFloat(float64(float32(v)), 'g', -1, 32)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func main() {
+	max := flag.Int("max", 1024, "largest power of 2 kernel to generate")
+	flag.Parse()
+
+	if *max < 16 || *max&(*max-1) != 0 {
+		log.Fatalf("-max %d is not a power of 2 of at least 16", *max)
+	}
+
+	for _, v := range []variant{
+		{File: "static.go", Type: "float64", Bits: 64},
+		{File: "static_f32.go", Type: "float32", Suffix: "F32", Bits: 32},
+	} {
+		d := data{variant: v}
+		for n := *max; n >= 2; n /= 2 {
+			if n >= 16 {
+				d.Kernels = append(d.Kernels, kernel{N: n, Half: n / 2})
+			}
+
+			t := table{N: n, Half: n / 2}
+			for i := 0; i < n/2; i += 8 {
+				var row []string
+				for j := i; j < i+8 && j < n/2; j++ {
+					row = append(row, literal(coef(n, j), v.Bits))
+				}
+				t.Rows = append(t.Rows, row)
+			}
+			d.Tables = append(d.Tables, t)
+		}
+		for n := 4; n <= *max; n *= 2 {
+			d.Sizes = append(d.Sizes, n)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, d); err != nil {
+			log.Fatal(err)
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			log.Fatalf("%s: %v\n%s", v.File, err, buf.Bytes())
+		}
+		if err := os.WriteFile(v.File, src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("wrote", v.File)
+	}
+}
+
+var tmpl = template.Must(template.New("static").Parse(`// Code generated by internal/gen; DO NOT EDIT.
+
+package dct
+
+// DCT type II, unscaled. Algorithm by Byeong Gi Lee, 1984.
+// Static implementation by Evan Oberholster, 2022.
+{{range .Kernels}}
+func transformDCT{{.N}}{{$.Suffix}}(input []{{$.Type}}) {
+	var temp [{{.N}}]{{$.Type}}
+	for i := 0; i < {{.Half}}; i++ {
+		x, y := input[i], input[{{.N}}-1-i]
+		temp[i] = x + y
+		temp[i+{{.Half}}] = (x - y) / dct{{.N}}{{$.Suffix}}[i]
+	}
+	transformDCT{{.Half}}{{$.Suffix}}(temp[:{{.Half}}])
+	transformDCT{{.Half}}{{$.Suffix}}(temp[{{.Half}}:])
+	for i := 0; i < {{.Half}}-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+{{.Half}}] + temp[i+{{.Half}}+1]
+	}
+	input[{{.N}}-2], input[{{.N}}-1] = temp[{{.Half}}-1], temp[{{.N}}-1]
+}
+{{end}}
+func transformDCT8{{.Suffix}}(input []{{.Type}}) {
+	a, b := [4]{{.Type}}{}, [4]{{.Type}}{}
+
+	x0, y0 := input[0], input[7]
+	x1, y1 := input[1], input[6]
+	x2, y2 := input[2], input[5]
+	x3, y3 := input[3], input[4]
+
+	a[0] = x0 + y0
+	a[1] = x1 + y1
+	a[2] = x2 + y2
+	a[3] = x3 + y3
+	b[0] = (x0 - y0) / dct8{{.Suffix}}[0]
+	b[1] = (x1 - y1) / dct8{{.Suffix}}[1]
+	b[2] = (x2 - y2) / dct8{{.Suffix}}[2]
+	b[3] = (x3 - y3) / dct8{{.Suffix}}[3]
+
+	transformDCT4{{.Suffix}}(a[:])
+	transformDCT4{{.Suffix}}(b[:])
+
+	input[0] = a[0]
+	input[1] = b[0] + b[1]
+	input[2] = a[1]
+	input[3] = b[1] + b[2]
+	input[4] = a[2]
+	input[5] = b[2] + b[3]
+	input[6] = a[3]
+	input[7] = b[3]
+}
+
+func transformDCT4{{.Suffix}}(input []{{.Type}}) {
+	x0, y0 := input[0], input[3]
+	x1, y1 := input[1], input[2]
+
+	t0 := x0 + y0
+	t1 := x1 + y1
+	t2 := (x0 - y0) / dct4{{.Suffix}}[0]
+	t3 := (x1 - y1) / dct4{{.Suffix}}[1]
+
+	x, y := t0, t1
+	t0 += t1
+	t1 = (x - y) / dct2{{.Suffix}}[0]
+
+	x, y = t2, t3
+	t2 += t3
+	t3 = (x - y) / dct2{{.Suffix}}[0]
+
+	input[0] = t0
+	input[1] = t2 + t3
+	input[2] = t1
+	input[3] = t3
+}
+
+// static_dct_kernel_{{if .Suffix}}f32{{else}}f64{{end}} returns the kernel for sizes with a static table, or nil.
+func static_dct_kernel_{{if .Suffix}}f32{{else}}f64{{end}}(n int) func([]{{.Type}}) {
+	switch n {
+{{- range .Sizes}}
+	case {{.}}:
+{{- if eq . 8}}
+		return fct8_1d[{{$.Type}}]
+{{- else}}
+		return transformDCT{{.}}{{$.Suffix}}
+{{- end}}
+{{- end}}
+	}
+	return nil
+}
+
+// Static DCT Tables, dct{N}[i] = 2 cos((i+0.5) pi / N), the divisors of
+// every level of the recursion.
+var (
+{{- range .Tables}}
+	dct{{.N}}{{$.Suffix}} = [{{.Half}}]{{$.Type}}{
+{{- range .Rows}}
+		{{range .}}{{.}}, {{end}}
+{{- end}}
+	}
+{{- end}}
+)
+`))
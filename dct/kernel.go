@@ -0,0 +1,96 @@
+package dct
+
+//go:generate go run ./internal/gen
+
+// static_dct_kernel returns the unrolled in place DCT-II for sizes with static
+// tables, or nil.
+func static_dct_kernel[T Float](n int) func([]T) {
+	var kernel any
+	if _, ok := any(T(0)).(float32); ok {
+		kernel = static_dct_kernel_f32(n)
+	} else {
+		kernel = static_dct_kernel_f64(n)
+	}
+	fn, _ := kernel.(func([]T))
+	return fn
+}
+
+// NewDCT1DKernel returns an in place n point unscaled DCT-II, as DCT_1D,
+// with its tables built once so it can be applied to many rows. It keeps
+// scratch space, so each goroutine needs its own.
+func NewDCT1DKernel[T Float](n int) func([]T) {
+	if n <= 1 {
+		return func([]T) {}
+	}
+	return dct_1d_kernel[T](n)
+}
+
+// dct_1d_kernel returns an in place n point DCT-II, fast where n is a power of 2
+// and through an FFT for other sizes from fft_threshold. Smaller sizes use a
+// precomputed coefficient table. Both keep a scratch buffer, so a kernel must
+// not be shared between goroutines.
+func dct_1d_kernel[T Float](n int) func([]T) {
+	if fast := static_dct_kernel[T](n); fast != nil {
+		return fast
+	}
+
+	if n&(n-1) == 0 {
+		coef := make([]T, n)
+		fast_dct_coef(n, coef)
+		return func(buf []T) { fast_dct_1d_precalc(buf, n, coef) }
+	}
+
+	if n >= fft_threshold {
+		return fft_dct_kernel[T](n, false)
+	}
+
+	coef := make([][]T, n)
+	for i := range coef {
+		coef[i] = make([]T, n)
+	}
+	dct_coef(n, coef)
+	temp := make([]T, n)
+
+	return func(buf []T) {
+		for i := 0; i < n; i++ {
+			var sum T
+			for j := 0; j < n; j++ {
+				sum += buf[j] * coef[j][i]
+			}
+			temp[i] = sum
+		}
+		copy(buf, temp)
+	}
+}
+
+// idct_1d_kernel returns an in place n point inverse of dct_1d_kernel.
+func idct_1d_kernel[T Float](n int) func([]T) {
+	if n&(n-1) == 0 {
+		coef := make([]T, n)
+		fast_dct_coef(n, coef)
+		return func(buf []T) { fast_idct_1d_precalc(buf, n, coef) }
+	}
+
+	if n >= fft_threshold {
+		return fft_dct_kernel[T](n, true)
+	}
+
+	coef := make([][]T, n)
+	for i := range coef {
+		coef[i] = make([]T, n)
+	}
+	idct_coef(n, coef)
+	temp := make([]T, n)
+	scale := T(2.0 / float64(n))
+
+	return func(buf []T) {
+		for i := 0; i < n; i++ {
+			sum := buf[0] / 2.0
+			for j := 1; j < n; j++ {
+				sum += buf[j] * coef[j][i]
+			}
+			temp[i] = sum * scale
+		}
+		copy(buf, temp)
+	}
+}
@@ -0,0 +1,106 @@
+//go:build amd64 && !purego
+
+package dct
+
+// hasAVX2 reports whether the CPU and OS support AVX2. useAVX2 selects the
+// assembly kernels and may be cleared to force the pure Go path.
+var (
+	hasAVX2 = cpu_has_avx2()
+	useAVX2 = hasAVX2
+)
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+//go:noescape
+func fct8_cols_avx2(block *[64]float64, scale *[8]float64)
+
+//go:noescape
+func lee_cols_avx2(dst, src *float64, stride, n int, coef, buf *float64)
+
+func cpu_has_avx2() bool {
+	max_id, _, _, _ := cpuid(0, 0)
+	if max_id < 7 {
+		return false
+	}
+
+	_, _, ecx, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx&osxsave == 0 || ecx&avx == 0 {
+		return false
+	}
+
+	// the OS must save the XMM and YMM state
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 {
+		return false
+	}
+
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx&(1<<5) != 0
+}
+
+// fct8_2d_asm is fct8_2d_scaled on a float64 block. The row pass is done as
+// a column pass between transposes, so the output is bit-identical to the Go
+// code. It reports false, leaving inbuf untouched, without AVX2.
+func fct8_2d_asm(inbuf []float64, scale *[8]float64) bool {
+	if !useAVX2 {
+		return false
+	}
+
+	block := (*[64]float64)(inbuf)
+	var temp [64]float64
+	transpose_8x8(&temp, block)
+	fct8_cols_avx2(&temp, scale)
+	transpose_8x8(block, &temp)
+	fct8_cols_avx2(block, scale)
+	return true
+}
+
+func transpose_8x8(dst, src *[64]float64) {
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			dst[x*8+y] = src[y*8+x]
+		}
+	}
+}
+
+// dct32_coef is fast_dct_coef(32) assembled from the static tables, so the
+// assembly divides by exactly the constants transformDCT32 does.
+var dct32_coef = func() (coef [32]float64) {
+	copy(coef[1:], dct2[:])
+	copy(coef[2:], dct4[:])
+	copy(coef[4:], dct8[:])
+	copy(coef[8:], dct16[:])
+	copy(coef[16:], dct32[:])
+	return coef
+}()
+
+// dct32_2d_asm is the body of DCT2DFast32E: transformDCT32 over the rows of
+// input in place, then over the columns into output. It reports false
+// without AVX2.
+func dct32_2d_asm(input []float64, output *[32 * 32]float64) bool {
+	if !useAVX2 {
+		return false
+	}
+
+	_ = input[32*32-1]
+	var temp [32 * 32]float64
+	var buf [8 * 32]float64
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			temp[x*32+y] = input[y*32+x]
+		}
+	}
+	for x := 0; x < 32; x += 4 {
+		lee_cols_avx2(&temp[x], &temp[x], 32, 32, &dct32_coef[0], &buf[0])
+	}
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			input[x*32+y] = temp[y*32+x]
+		}
+	}
+	for x := 0; x < 32; x += 4 {
+		lee_cols_avx2(&output[x], &input[x], 32, 32, &dct32_coef[0], &buf[0])
+	}
+	return true
+}
@@ -0,0 +1,251 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// AAN constants of fct8_1d_scaled and the sign bit for negation
+DATA fct8c<>+0(SB)/8, $0x8000000000000000  // -0
+DATA fct8c<>+8(SB)/8, $0x3fe6a09e667f3bcd  // 0.707106781186547524400844
+DATA fct8c<>+16(SB)/8, $0x3fd87de2a6aea963 // 0.382683432365089771728460
+DATA fct8c<>+24(SB)/8, $0x3fe1517a7bdb3895 // 0.541196100146196984399723
+DATA fct8c<>+32(SB)/8, $0x3ff4e7ae9144f0fc // 1.306562964876376527856643
+GLOBL fct8c<>(SB), RODATA|NOPTR, $40
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// fct8_half runs fct8_1d_scaled down 4 columns of the 8x8 block at DI,
+// starting at byte offset off, with the output scaling at SI. Each step
+// mirrors the Go code operation for operation, without FMA, so the result
+// is bit-identical.
+#define fct8_half(off) \
+	VMOVUPD off+0(DI), Y0    \
+	VMOVUPD off+64(DI), Y1   \
+	VMOVUPD off+128(DI), Y2  \
+	VMOVUPD off+192(DI), Y3  \
+	VMOVUPD off+256(DI), Y4  \
+	VMOVUPD off+320(DI), Y5  \
+	VMOVUPD off+384(DI), Y6  \
+	VMOVUPD off+448(DI), Y7  \
+	VADDPD  Y7, Y0, Y8         /* v0 */ \
+	VSUBPD  Y7, Y0, Y7         /* v7 */ \
+	VADDPD  Y6, Y1, Y9         /* v1 */ \
+	VSUBPD  Y6, Y1, Y6         /* v6 */ \
+	VADDPD  Y5, Y2, Y10        /* v2 */ \
+	VSUBPD  Y5, Y2, Y5         /* v5 */ \
+	VADDPD  Y4, Y3, Y11        /* v3 */ \
+	VSUBPD  Y4, Y3, Y4         /* v4 */ \
+	VADDPD  Y11, Y8, Y0        /* v8 */ \
+	VSUBPD  Y11, Y8, Y3        /* v11 */ \
+	VADDPD  Y10, Y9, Y1        /* v9 */ \
+	VSUBPD  Y10, Y9, Y2        /* v10 */ \
+	VXORPD  Y15, Y4, Y8        \
+	VSUBPD  Y5, Y8, Y8         /* v12 = -v4 - v5 */ \
+	VADDPD  Y6, Y5, Y9         \
+	VMULPD  Y14, Y9, Y9        /* v13 */ \
+	VADDPD  Y7, Y6, Y10        /* v14 */ \
+	VADDPD  Y1, Y0, Y4         /* v15 */ \
+	VSUBPD  Y1, Y0, Y5         /* v16 */ \
+	VADDPD  Y3, Y2, Y6         \
+	VMULPD  Y14, Y6, Y6        /* v17 */ \
+	VADDPD  Y10, Y8, Y0        \
+	VBROADCASTSD fct8c<>+16(SB), Y13 \
+	VMULPD  Y13, Y0, Y0        /* v18 */ \
+	VXORPD  Y15, Y8, Y1        \
+	VBROADCASTSD fct8c<>+24(SB), Y13 \
+	VMULPD  Y13, Y1, Y1        \
+	VSUBPD  Y0, Y1, Y1         /* v19 = -v12*c - v18 */ \
+	VBROADCASTSD fct8c<>+32(SB), Y13 \
+	VMULPD  Y13, Y10, Y2       \
+	VSUBPD  Y0, Y2, Y2         /* v20 */ \
+	VADDPD  Y3, Y6, Y8         /* v21 */ \
+	VSUBPD  Y6, Y3, Y10        /* v22 */ \
+	VADDPD  Y7, Y9, Y0         /* v23 */ \
+	VSUBPD  Y9, Y7, Y3         /* v24 */ \
+	VADDPD  Y3, Y1, Y6         /* v25 */ \
+	VADDPD  Y2, Y0, Y7         /* v26 */ \
+	VSUBPD  Y2, Y0, Y9         /* v27 */ \
+	VSUBPD  Y1, Y3, Y11        /* v28 */ \
+	VBROADCASTSD 0(SI), Y12    \
+	VMULPD  Y12, Y4, Y4        \
+	VMOVUPD Y4, off+0(DI)    \
+	VBROADCASTSD 8(SI), Y12    \
+	VMULPD  Y12, Y7, Y7        \
+	VMOVUPD Y7, off+64(DI)   \
+	VBROADCASTSD 16(SI), Y12   \
+	VMULPD  Y12, Y8, Y8        \
+	VMOVUPD Y8, off+128(DI)  \
+	VBROADCASTSD 24(SI), Y12   \
+	VMULPD  Y12, Y11, Y11      \
+	VMOVUPD Y11, off+192(DI) \
+	VBROADCASTSD 32(SI), Y12   \
+	VMULPD  Y12, Y5, Y5        \
+	VMOVUPD Y5, off+256(DI)  \
+	VBROADCASTSD 40(SI), Y12   \
+	VMULPD  Y12, Y6, Y6        \
+	VMOVUPD Y6, off+320(DI)  \
+	VBROADCASTSD 48(SI), Y12   \
+	VMULPD  Y12, Y10, Y10      \
+	VMOVUPD Y10, off+384(DI) \
+	VBROADCASTSD 56(SI), Y12   \
+	VMULPD  Y12, Y9, Y9        \
+	VMOVUPD Y9, off+448(DI)
+
+// func fct8_cols_avx2(block *[64]float64, scale *[8]float64)
+TEXT ·fct8_cols_avx2(SB), NOSPLIT, $0-16
+	MOVQ block+0(FP), DI
+	MOVQ scale+8(FP), SI
+	VBROADCASTSD fct8c<>+0(SB), Y15
+	VBROADCASTSD fct8c<>+8(SB), Y14
+	fct8_half(0)
+	fct8_half(32)
+	VZEROUPPER
+	RET
+
+// func lee_cols_avx2(dst, src *float64, stride, n int, coef, buf *float64)
+//
+// Runs the n point Lee DCT-II of transform_recursive down 4 adjacent columns
+// of a row major matrix with stride elements per row, reading src and writing
+// dst, which may be the same. coef holds fast_dct_coef(n) and buf 8n
+// elements of scratch. The recursion is flattened into log2(n) butterfly
+// passes followed by log2(n)-1 recombination passes, ping-ponging between
+// the two halves of buf, one column per YMM lane.
+TEXT ·lee_cols_avx2(SB), NOSPLIT, $0-48
+	MOVQ src+8(FP), SI
+	MOVQ stride+16(FP), DX
+	SHLQ $3, DX
+	MOVQ n+24(FP), CX
+	MOVQ coef+32(FP), R8
+	MOVQ buf+40(FP), R9
+	MOVQ CX, R13
+	SHLQ $5, R13                // R13 = n rows of 32 bytes
+	LEAQ (R9)(R13*1), R10       // R9 = A, R10 = B
+
+	// gather the columns into A
+	MOVQ R9, DI
+
+gather:
+	VMOVUPD (SI), Y0
+	VMOVUPD Y0, (DI)
+	ADDQ    DX, SI
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     gather
+
+	// butterflies, len = n down to 2, R11 = len*32, R12 = len*16
+	MOVQ R13, R11
+
+fwd:
+	CMPQ R11, $64
+	JLT  fwd_done
+	MOVQ R11, R12
+	SHRQ $1, R12
+	XORQ BX, BX
+
+fwd_block:
+	LEAQ (R9)(BX*1), SI         // x[i]
+	LEAQ -32(SI)(R11*1), DX     // x[len-1-i]
+	LEAQ (R10)(BX*1), DI        // temp[i]
+	MOVQ R12, AX
+	SHRQ $2, AX
+	ADDQ R8, AX                 // coef[len/2+i]
+	MOVQ R12, CX
+	SHRQ $5, CX
+
+fwd_loop:
+	VMOVUPD      (SI), Y0
+	VMOVUPD      (DX), Y1
+	VADDPD       Y1, Y0, Y2
+	VSUBPD       Y1, Y0, Y3
+	VBROADCASTSD (AX), Y4
+	VDIVPD       Y4, Y3, Y3
+	VMOVUPD      Y2, (DI)
+	VMOVUPD      Y3, (DI)(R12*1)
+	ADDQ         $32, SI
+	SUBQ         $32, DX
+	ADDQ         $32, DI
+	ADDQ         $8, AX
+	DECQ         CX
+	JNZ          fwd_loop
+
+	ADDQ  R11, BX
+	CMPQ  BX, R13
+	JLT   fwd_block
+	XCHGQ R9, R10
+	MOVQ  R12, R11
+	JMP   fwd
+
+fwd_done:
+	// recombination, len = 4 up to n
+	MOVQ $128, R11
+
+bwd:
+	CMPQ R11, R13
+	JGT  bwd_done
+	MOVQ R11, R12
+	SHRQ $1, R12
+	XORQ BX, BX
+
+bwd_block:
+	LEAQ (R9)(BX*1), SI         // temp[i]
+	LEAQ (R10)(BX*1), DI        // x[2i]
+	MOVQ R12, CX
+	SHRQ $5, CX
+	DECQ CX
+
+bwd_loop:
+	VMOVUPD (SI), Y0
+	VMOVUPD Y0, (DI)
+	VMOVUPD (SI)(R12*1), Y1
+	VMOVUPD 32(SI)(R12*1), Y2
+	VADDPD  Y2, Y1, Y1
+	VMOVUPD Y1, 32(DI)
+	ADDQ    $32, SI
+	ADDQ    $64, DI
+	DECQ    CX
+	JNZ     bwd_loop
+
+	VMOVUPD (SI), Y0
+	VMOVUPD Y0, (DI)
+	VMOVUPD (SI)(R12*1), Y1
+	VMOVUPD Y1, 32(DI)
+
+	ADDQ  R11, BX
+	CMPQ  BX, R13
+	JLT   bwd_block
+	XCHGQ R9, R10
+	SHLQ  $1, R11
+	JMP   bwd
+
+bwd_done:
+	// scatter A back to the columns of dst
+	MOVQ dst+0(FP), DI
+	MOVQ stride+16(FP), DX
+	SHLQ $3, DX
+	MOVQ n+24(FP), CX
+
+scatter:
+	VMOVUPD (R9), Y0
+	VMOVUPD Y0, (DI)
+	ADDQ    DX, DI
+	ADDQ    $32, R9
+	DECQ    CX
+	JNZ     scatter
+
+	VZEROUPPER
+	RET
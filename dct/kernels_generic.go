@@ -0,0 +1,17 @@
+//go:build !amd64 || purego
+
+package dct
+
+// There are no assembly kernels on this platform.
+var (
+	hasAVX2 = false
+	useAVX2 = false
+)
+
+func fct8_2d_asm(inbuf []float64, scale *[8]float64) bool {
+	return false
+}
+
+func dct32_2d_asm(input []float64, output *[32 * 32]float64) bool {
+	return false
+}
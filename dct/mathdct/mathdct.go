@@ -0,0 +1,120 @@
+// Package mathdct mirrors the calling conventions of Perl's Math::DCT, which
+// the dct package is ported from, so code and test data written against the
+// Perl module carry over unchanged.
+//
+// dct1d, dct2d, idct1d and idct2d take a flat array and an optional size,
+// where a missing or zero size is inferred from the length of the array: the
+// length itself in 1D and its square root, truncated, in 2D. The input is
+// truncated to the size, or padded with zeros where Math::DCT would read past
+// its end. dct and idct take an array of arrays, 1 x N or N x N, and die with
+// the messages of ErrArrayOfArrays and ErrShape otherwise.
+package mathdct
+
+import (
+	"errors"
+	"math"
+
+	"go.local/go-image-phash/dct"
+)
+
+// The errors are worded, and capitalised, exactly as Math::DCT's croaks.
+var (
+	ErrArrayOfArrays = errors.New("Expect array of array(s)")
+	ErrShape         = errors.New("Expect 1d or NxN 2d arrays")
+)
+
+// Dct1d is dct1d(\@array, $size): the unscaled DCT-II of the first size
+// elements of array.
+func Dct1d(array []float64, size ...int) []float64 {
+	sz := size_arg(size, len(array))
+	if sz == 0 {
+		return []float64{}
+	}
+	return dct.DCT_1D(resize(array, sz), sz)
+}
+
+// Dct2d is dct2d(\@array, $size): the unscaled 2D DCT-II of the flattened
+// size x size matrix at the start of array.
+func Dct2d(array []float64, size ...int) []float64 {
+	sz := size_arg(size, int(math.Sqrt(float64(len(array)))))
+	if sz == 0 {
+		return []float64{}
+	}
+	return dct.DCT_2D(resize(array, sz*sz), sz)
+}
+
+// Idct1d is idct1d(\@array, $size), the inverse of Dct1d.
+func Idct1d(array []float64, size ...int) []float64 {
+	sz := size_arg(size, len(array))
+	if sz == 0 {
+		return []float64{}
+	}
+	return dct.IDCT_1D(resize(array, sz), sz)
+}
+
+// Idct2d is idct2d(\@array, $size), the inverse of Dct2d.
+func Idct2d(array []float64, size ...int) []float64 {
+	sz := size_arg(size, int(math.Sqrt(float64(len(array)))))
+	if sz == 0 {
+		return []float64{}
+	}
+	return dct.IDCT_2D(resize(array, sz*sz), sz)
+}
+
+// Dct is dct(\@arrays): Dct1d of a single row or Dct2d of an N x N matrix,
+// in the same shape.
+func Dct(arrays [][]float64) ([][]float64, error) {
+	return transform(arrays, Dct1d, Dct2d)
+}
+
+// Idct is idct(\@arrays), the inverse of Dct.
+func Idct(arrays [][]float64) ([][]float64, error) {
+	return transform(arrays, Idct1d, Idct2d)
+}
+
+// transform checks the shape as Math::DCT does, on the number of rows and the
+// length of the first one only, and then transforms the rows joined
+// together, so ragged rows shift into each other rather than failing.
+func transform(arrays [][]float64, f1d, f2d func([]float64, ...int) []float64) ([][]float64, error) {
+	if len(arrays) == 0 {
+		return nil, ErrArrayOfArrays
+	}
+
+	dim, sz := len(arrays), len(arrays[0])
+	if dim != 1 && dim != sz {
+		return nil, ErrShape
+	}
+
+	var flat []float64
+	for _, row := range arrays {
+		flat = append(flat, row...)
+	}
+
+	var out []float64
+	if dim == 1 {
+		out = f1d(flat, sz)
+	} else {
+		out = f2d(flat, sz)
+	}
+
+	result := make([][]float64, dim)
+	for i := range result {
+		result[i] = out[i*sz : (i+1)*sz : (i+1)*sz]
+	}
+	return result, nil
+}
+
+// size_arg is Perl's `shift || $default` for the optional size.
+func size_arg(size []int, def int) int {
+	if len(size) > 0 && size[0] != 0 {
+		return size[0]
+	}
+	return def
+}
+
+// resize returns a copy of the first n elements of array, padded with zeros.
+func resize(array []float64, n int) []float64 {
+	out := make([]float64, n)
+	copy(out, array)
+	return out
+}
@@ -0,0 +1,132 @@
+package mathdct
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const (
+	EPSILON float64 = 0.00000001
+)
+
+func near(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > EPSILON {
+			return false
+		}
+	}
+	return true
+}
+
+// The calls of perl/test-001.pl, with the outputs of Math::DCT. test-001.pl
+// also passes [[1, 2, 3, 4]] to idct1d and idct2d, which take a flat array:
+// Perl reads the nested reference as one number, its address, so there is
+// nothing to reproduce, and the flat [1, 2, 3, 4] is used instead.
+func TestPerlScripts(t *testing.T) {
+	for _, tt := range []struct {
+		input  [][]float64
+		output [][]float64
+	}{
+		{
+			[][]float64{{0.3181653197002592, 0.39066343796185155, 0.16102608753078032}},
+			[][]float64{{0.869854845192891, 0.13608656698994964, -0.15106773434633186}},
+		},
+		{
+			// also perl/test-002.pl
+			[][]float64{{1, 2}, {3, 4}},
+			[][]float64{{10, -1.41421356237309}, {-2.82842712474619, 0}},
+		},
+		{
+			[][]float64{{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}},
+			[][]float64{{136, -51.79283109806665, 0, -5.678147121159544, 0, -1.9843883778092195, 0, -0.9603691873837708,
+				0, -0.5308329190494803, 0, -0.30303790007019327, 0, -0.1584982220313611, 0, -0.04948398057023362}},
+		},
+	} {
+		out, err := Dct(tt.input)
+		if err != nil {
+			t.Fatalf("Dct(%v) returned error %v", tt.input, err)
+		}
+
+		pass := len(out) == len(tt.output)
+		for i := 0; pass && i < len(out); i++ {
+			pass = near(out[i], tt.output[i])
+		}
+		if !pass {
+			t.Errorf("Dct(%v) expected %v but got %v.", tt.input, tt.output, out)
+		}
+
+		back, err := Idct(out)
+		if err != nil {
+			t.Fatalf("Idct(%v) returned error %v", out, err)
+		}
+		for i := range back {
+			if !near(back[i], tt.input[i]) {
+				t.Errorf("Idct(%v) expected %v but got %v.", out, tt.input, back)
+			}
+		}
+	}
+
+	in := []float64{1, 2, 3, 4}
+	if out, want := Idct1d(in), []float64{2.999906569021288, -2.2757358044373053, 0.654415460877662, -0.37858622546164555}; !near(out, want) {
+		t.Errorf("Idct1d(%v) expected %v but got %v.", in, want, out)
+	}
+	if out, want := Idct2d(in), []float64{4.017766952966369, -1.396446609406726, -2.1035533905932735, 0.482233047033631}; !near(out, want) {
+		t.Errorf("Idct2d(%v) expected %v but got %v.", in, want, out)
+	}
+}
+
+func TestSize(t *testing.T) {
+	in := []float64{1, 2, 3, 4, 5}
+	for _, tt := range []struct {
+		name   string
+		out    []float64
+		output []float64
+	}{
+		{"Dct1d inferred", Dct1d(in), Dct1d(in, 5)},
+		{"Dct1d zero", Dct1d(in, 0), Dct1d(in, 5)},
+		{"Dct1d truncated", Dct1d(in, 2), []float64{3, -0.7071067811865476}},
+		{"Dct1d padded", Dct1d(in[:2], 4), Dct1d([]float64{1, 2, 0, 0})},
+		{"Dct2d inferred", Dct2d(in), Dct2d(in[:4], 2)}, // int(sqrt(5)) = 2
+		{"Dct2d zero", Dct2d(in, 0), Dct2d(in[:4])},
+		{"Dct2d padded", Dct2d(in[:2], 2), []float64{3, -0.7071067811865476, 2.1213203435596424, -0.5}},
+		{"Idct1d truncated", Idct1d(in, 1), []float64{1}},
+		{"Idct2d inferred", Idct2d(in), Idct2d(in[:4], 2)},
+		{"empty", Dct1d(nil), []float64{}},
+	} {
+		if !near(tt.out, tt.output) {
+			t.Errorf("%s: expected %v but got %v.", tt.name, tt.output, tt.out)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		input [][]float64
+		err   error
+		msg   string
+	}{
+		{nil, ErrArrayOfArrays, "Expect array of array(s)"},
+		{[][]float64{{1, 2}, {3, 4}, {5, 6}}, ErrShape, "Expect 1d or NxN 2d arrays"},
+		{[][]float64{{1, 2, 3}, {4, 5, 6}}, ErrShape, "Expect 1d or NxN 2d arrays"},
+	} {
+		for _, f := range []func([][]float64) ([][]float64, error){Dct, Idct} {
+			_, err := f(tt.input)
+			if !errors.Is(err, tt.err) || err.Error() != tt.msg {
+				t.Errorf("%v: expected %q but got %v.", tt.input, tt.msg, err)
+			}
+		}
+	}
+
+	// only the first row sets the width, as in Math::DCT
+	out, err := Dct([][]float64{{1, 2}, {3, 4, 5}})
+	if err != nil {
+		t.Fatalf("ragged input returned error %v", err)
+	}
+	if want := Dct2d([]float64{1, 2, 3, 4}); !near(append(out[0], out[1]...), want) {
+		t.Errorf("ragged input expected %v but got %v.", want, out)
+	}
+}
@@ -0,0 +1,157 @@
+package dct
+
+// Matrix is a Rows x Cols window of Data, row i starting at Data[i*Stride].
+// A Stride wider than Cols lets a Matrix view a region of a larger image, so
+// it can be transformed in place without copying it out. DCTMatrix and the
+// DCT-I, DCT-IV and DST Matrix functions take it, and MatrixF32 is the same
+// for float32. The fixed-point DCT2DUint8 and DCT2DInt32 take integer samples
+// and have no Matrix form.
+type Matrix struct {
+	Data       []float64
+	Rows, Cols int
+	Stride     int
+}
+
+// NewMatrix returns a zeroed rows x cols Matrix.
+func NewMatrix(rows, cols int) Matrix {
+	return Matrix{Data: make([]float64, rows*cols), Rows: rows, Cols: cols, Stride: cols}
+}
+
+// MatrixOf packs rows into a new Matrix. It returns ErrInvalidInput if rows is
+// empty and ErrInvalidSize if they differ in length.
+func MatrixOf(rows [][]float64) (Matrix, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return Matrix{}, ErrInvalidInput
+	}
+
+	m := NewMatrix(len(rows), len(rows[0]))
+	for i, row := range rows {
+		if len(row) != m.Cols {
+			return Matrix{}, ErrInvalidSize
+		}
+		copy(m.Row(i), row)
+	}
+	return m, nil
+}
+
+// At returns the element in row i, column j.
+func (m Matrix) At(i, j int) float64 {
+	return m.Data[i*m.Stride+j]
+}
+
+// Set sets the element in row i, column j.
+func (m Matrix) Set(i, j int, v float64) {
+	m.Data[i*m.Stride+j] = v
+}
+
+// Row returns row i, sharing its storage with m.
+func (m Matrix) Row(i int) []float64 {
+	return m.Data[i*m.Stride : i*m.Stride+m.Cols : i*m.Stride+m.Cols]
+}
+
+// Sub returns the rows x cols window of m with its top left corner at row i,
+// column j, sharing its storage with m.
+func (m Matrix) Sub(i, j, rows, cols int) Matrix {
+	if i < 0 || j < 0 || rows <= 0 || cols <= 0 || i+rows > m.Rows || j+cols > m.Cols {
+		panic("dct: Matrix.Sub out of range")
+	}
+	return Matrix{Data: m.Data[i*m.Stride+j:], Rows: rows, Cols: cols, Stride: m.Stride}
+}
+
+// Slices returns the rows of m, sharing their storage with m.
+func (m Matrix) Slices() [][]float64 {
+	rows := make([][]float64, m.Rows)
+	for i := range rows {
+		rows[i] = m.Row(i)
+	}
+	return rows
+}
+
+// Contiguous reports whether the rows of m follow each other in Data.
+func (m Matrix) Contiguous() bool {
+	return m.Stride == m.Cols || m.Rows == 1
+}
+
+func (m Matrix) check() error {
+	return check_strided(len(m.Data), m.Rows, m.Cols, m.Stride)
+}
+
+// check_strided returns ErrInvalidInput unless n elements hold a rows x cols
+// matrix with rows stride elements apart.
+func check_strided(n, rows, cols, stride int) error {
+	if rows <= 0 || cols <= 0 || stride < cols || n < (rows-1)*stride+cols {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// DCTMatrix replaces m with its DCT-II in place, along both axes, or along the
+// row for a single row. Axes whose length is a power of 2 use the fast
+// transforms.
+func DCTMatrix(m Matrix) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+
+	if m.Rows == 1 {
+		dct_1d_kernel[float64](m.Cols)(m.Row(0))
+		return nil
+	}
+
+	if m.Rows == 8 && m.Cols == 8 && m.Contiguous() {
+		fct8_2d(m.Data[:64]) // Arai, Agui, Nakajima
+		return nil
+	}
+
+	separable_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, dct_1d_kernel[float64](m.Cols), dct_1d_kernel[float64](m.Rows))
+	return nil
+}
+
+// IDCTMatrix is the inverse of DCTMatrix.
+func IDCTMatrix(m Matrix) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+
+	if m.Rows == 1 {
+		idct_1d_kernel[float64](m.Cols)(m.Row(0))
+		return nil
+	}
+
+	separable_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, idct_1d_kernel[float64](m.Cols), idct_1d_kernel[float64](m.Rows))
+	return nil
+}
+
+// NormalizeMatrix is Normalize for the DCTMatrix coefficients in m.
+func NormalizeMatrix(m Matrix, norm Norm) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	scale_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, norm, false)
+	return nil
+}
+
+// DenormalizeMatrix undoes NormalizeMatrix.
+func DenormalizeMatrix(m Matrix, norm Norm) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	scale_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, norm, true)
+	return nil
+}
+
+// BlockDCTMatrix is BlockDCT2D over the n x n blocks of m.
+func BlockDCTMatrix(m Matrix, n int) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	return block_2d(m, n, block_dct_kernel(n))
+}
+
+// BlockIDCTMatrix is the inverse of BlockDCTMatrix.
+func BlockIDCTMatrix(m Matrix, n int) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	return block_2d(m, n, block_idct_kernel(n))
+}
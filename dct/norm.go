@@ -0,0 +1,145 @@
+package dct
+
+import (
+	"math"
+	"slices"
+)
+
+// Norm selects the scaling of DCT-II coefficients. The inverse transforms take
+// the same Norm, so a forward/inverse round trip is exact in every mode.
+type Norm int
+
+const (
+	// NormNone is the unscaled DCT-II of Math::DCT,
+	// X[k] = sum x[n] cos(pi/N (n+0.5) k). FFTW's REDFT10 and scipy's
+	// norm=None are twice this.
+	NormNone Norm = iota
+
+	// NormOrtho is the orthonormal DCT-II, sqrt(2/N) c[k] times NormNone with
+	// c[0] = 1/sqrt(2), as scipy's norm='ortho'.
+	NormOrtho
+
+	// NormJPEG is the ITU-T T.81 scaling, c[k]/2 times NormNone per axis.
+	// For the 8 point transforms JPEG uses this equals NormOrtho.
+	NormJPEG
+)
+
+// norm_factor returns the factor taking coefficient k of an unscaled n point
+// DCT-II to the norm scaling.
+func norm_factor(norm Norm, n, k int) float64 {
+	switch norm {
+	case NormOrtho:
+		if k == 0 {
+			return math.Sqrt(1 / float64(n))
+		}
+		return math.Sqrt(2 / float64(n))
+	case NormJPEG:
+		if k == 0 {
+			return 0.5 / math.Sqrt2
+		}
+		return 0.5
+	}
+	return 1
+}
+
+// Normalize scales the unscaled coefficients of a flattened rows x cols
+// transform, as returned by DCT_2D, DCT_MxN or the DCT2DFast functions, to
+// norm in place. Use rows = 1 for DCT_1D output.
+func Normalize(coeffs []float64, rows, cols int, norm Norm) {
+	scale_2d(coeffs, rows, cols, norm, false)
+}
+
+// Denormalize undoes Normalize, returning coefficients to NormNone in place.
+func Denormalize(coeffs []float64, rows, cols int, norm Norm) {
+	scale_2d(coeffs, rows, cols, norm, true)
+}
+
+func scale_2d(coeffs []float64, rows, cols int, norm Norm, inverse bool) {
+	scale_2d_strided(coeffs, rows, cols, cols, norm, inverse)
+}
+
+func scale_2d_strided(coeffs []float64, rows, cols, stride int, norm Norm, inverse bool) {
+	if norm == NormNone {
+		return
+	}
+
+	row_scale := make([]float64, rows)
+	for i := range row_scale {
+		row_scale[i] = norm_factor(norm, rows, i)
+	}
+	if rows == 1 {
+		row_scale[0] = 1
+	}
+
+	col_scale := make([]float64, cols)
+	for j := range col_scale {
+		col_scale[j] = norm_factor(norm, cols, j)
+	}
+
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if inverse {
+				coeffs[i*stride+j] /= row_scale[i] * col_scale[j]
+			} else {
+				coeffs[i*stride+j] *= row_scale[i] * col_scale[j]
+			}
+		}
+	}
+}
+
+// fct8_norm_scale folds norm into the AAN output scaling of fct8_1d.
+func fct8_norm_scale(norm Norm) *[8]float64 {
+	scale := fct8_scale
+	for k := range scale {
+		scale[k] *= norm_factor(norm, 8, k)
+	}
+	return &scale
+}
+
+// DCT_1D_Norm is DCT_1D with the coefficients scaled to norm.
+func DCT_1D_Norm(input []float64, sz int, norm Norm) []float64 {
+	if sz == 8 {
+		result := slices.Clone(input)
+		fct8_1d_scaled(result, fct8_norm_scale(norm))
+		return result
+	}
+
+	result := DCT_1D(input, sz)
+	Normalize(result, 1, sz, norm)
+	return result
+}
+
+// IDCT_1D_Norm is the inverse of DCT_1D_Norm.
+func IDCT_1D_Norm(input []float64, sz int, norm Norm) []float64 {
+	result := slices.Clone(input)
+	Denormalize(result, 1, sz, norm)
+	return IDCT_1D(result, sz)
+}
+
+// DCT_2D_Norm is DCT_2D with the coefficients scaled to norm.
+func DCT_2D_Norm(input []float64, sz int, norm Norm) []float64 {
+	if sz == 0 {
+		sz = int(math.Sqrt(float64(len(input))))
+	}
+
+	if sz == 8 {
+		result := slices.Clone(input)
+		fct8_2d_scaled(result, fct8_norm_scale(norm))
+		return result
+	}
+
+	result := DCT_2D(input, sz)
+	Normalize(result, sz, sz, norm)
+	return result
+}
+
+// IDCT_2D_Norm is the inverse of DCT_2D_Norm.
+func IDCT_2D_Norm(input []float64, sz int, norm Norm) []float64 {
+	if sz == 0 {
+		sz = int(math.Sqrt(float64(len(input))))
+	}
+
+	result := slices.Clone(input)
+	Denormalize(result, sz, sz, norm)
+	return IDCT_2D(result, sz)
+}
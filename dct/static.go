@@ -1,5 +1,42 @@
+// Code generated by internal/gen; DO NOT EDIT.
+
 package dct
 
+// DCT type II, unscaled. Algorithm by Byeong Gi Lee, 1984.
+// Static implementation by Evan Oberholster, 2022.
+
+func transformDCT1024(input []float64) {
+	var temp [1024]float64
+	for i := 0; i < 512; i++ {
+		x, y := input[i], input[1024-1-i]
+		temp[i] = x + y
+		temp[i+512] = (x - y) / dct1024[i]
+	}
+	transformDCT512(temp[:512])
+	transformDCT512(temp[512:])
+	for i := 0; i < 512-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+512] + temp[i+512+1]
+	}
+	input[1024-2], input[1024-1] = temp[512-1], temp[1024-1]
+}
+
+func transformDCT512(input []float64) {
+	var temp [512]float64
+	for i := 0; i < 256; i++ {
+		x, y := input[i], input[512-1-i]
+		temp[i] = x + y
+		temp[i+256] = (x - y) / dct512[i]
+	}
+	transformDCT256(temp[:256])
+	transformDCT256(temp[256:])
+	for i := 0; i < 256-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+256] + temp[i+256+1]
+	}
+	input[512-2], input[512-1] = temp[256-1], temp[512-1]
+}
+
 func transformDCT256(input []float64) {
 	var temp [256]float64
 	for i := 0; i < 128; i++ {
@@ -32,13 +69,10 @@ func transformDCT128(input []float64) {
 	input[128-2], input[128-1] = temp[64-1], temp[128-1]
 }
 
-// transformDCT64 function returns result of DCT-II.
-// DCT type II, unscaled. Algorithm by Byeong Gi Lee, 1984.
-// Static implementation by Evan Oberholster, 2022.
 func transformDCT64(input []float64) {
 	var temp [64]float64
 	for i := 0; i < 32; i++ {
-		x, y := input[i], input[63-i]
+		x, y := input[i], input[64-1-i]
 		temp[i] = x + y
 		temp[i+32] = (x - y) / dct64[i]
 	}
@@ -48,13 +82,13 @@ func transformDCT64(input []float64) {
 		input[i*2+0] = temp[i]
 		input[i*2+1] = temp[i+32] + temp[i+32+1]
 	}
-	input[62], input[63] = temp[31], temp[63]
+	input[64-2], input[64-1] = temp[32-1], temp[64-1]
 }
 
 func transformDCT32(input []float64) {
 	var temp [32]float64
 	for i := 0; i < 16; i++ {
-		x, y := input[i], input[31-i]
+		x, y := input[i], input[32-1-i]
 		temp[i] = x + y
 		temp[i+16] = (x - y) / dct32[i]
 	}
@@ -64,14 +98,13 @@ func transformDCT32(input []float64) {
 		input[i*2+0] = temp[i]
 		input[i*2+1] = temp[i+16] + temp[i+16+1]
 	}
-
-	input[30], input[31] = temp[15], temp[31]
+	input[32-2], input[32-1] = temp[16-1], temp[32-1]
 }
 
 func transformDCT16(input []float64) {
 	var temp [16]float64
 	for i := 0; i < 8; i++ {
-		x, y := input[i], input[15-i]
+		x, y := input[i], input[16-1-i]
 		temp[i] = x + y
 		temp[i+8] = (x - y) / dct16[i]
 	}
@@ -81,8 +114,7 @@ func transformDCT16(input []float64) {
 		input[i*2+0] = temp[i]
 		input[i*2+1] = temp[i+8] + temp[i+8+1]
 	}
-
-	input[14], input[15] = temp[7], temp[15]
+	input[16-2], input[16-1] = temp[8-1], temp[16-1]
 }
 
 func transformDCT8(input []float64) {
@@ -97,10 +129,10 @@ func transformDCT8(input []float64) {
 	a[1] = x1 + y1
 	a[2] = x2 + y2
 	a[3] = x3 + y3
-	b[0] = (x0 - y0) / 1.9615705608064609
-	b[1] = (x1 - y1) / 1.6629392246050907
-	b[2] = (x2 - y2) / 1.1111404660392046
-	b[3] = (x3 - y3) / 0.3901806440322566
+	b[0] = (x0 - y0) / dct8[0]
+	b[1] = (x1 - y1) / dct8[1]
+	b[2] = (x2 - y2) / dct8[2]
+	b[3] = (x3 - y3) / dct8[3]
 
 	transformDCT4(a[:])
 	transformDCT4(b[:])
@@ -121,16 +153,16 @@ func transformDCT4(input []float64) {
 
 	t0 := x0 + y0
 	t1 := x1 + y1
-	t2 := (x0 - y0) / 1.8477590650225735
-	t3 := (x1 - y1) / 0.7653668647301797
+	t2 := (x0 - y0) / dct4[0]
+	t3 := (x1 - y1) / dct4[1]
 
 	x, y := t0, t1
 	t0 += t1
-	t1 = (x - y) / 1.4142135623730951
+	t1 = (x - y) / dct2[0]
 
 	x, y = t2, t3
 	t2 += t3
-	t3 = (x - y) / 1.4142135623730951
+	t3 = (x - y) / dct2[0]
 
 	input[0] = t0
 	input[1] = t2 + t3
@@ -138,11 +170,134 @@ func transformDCT4(input []float64) {
 	input[3] = t3
 }
 
-// Static DCT Tables
+// static_dct_kernel_f64 returns the kernel for sizes with a static table, or nil.
+func static_dct_kernel_f64(n int) func([]float64) {
+	switch n {
+	case 4:
+		return transformDCT4
+	case 8:
+		return fct8_1d[float64]
+	case 16:
+		return transformDCT16
+	case 32:
+		return transformDCT32
+	case 64:
+		return transformDCT64
+	case 128:
+		return transformDCT128
+	case 256:
+		return transformDCT256
+	case 512:
+		return transformDCT512
+	case 1024:
+		return transformDCT1024
+	}
+	return nil
+}
+
+// Static DCT Tables, dct{N}[i] = 2 cos((i+0.5) pi / N), the divisors of
+// every level of the recursion.
 var (
-	//for i := 0; i < len(dct256); i++ {
-	//	dct256[i] = (math.Cos((float64(i)+0.5)*math.Pi/float64(256)) * 2)
-	//}
+	dct1024 = [512]float64{
+		1.9999976469034038, 1.9999788221638568, 1.999941172861948, 1.9998846993520478, 1.9998094021657056, 1.9997152820116477, 1.9996023397757683, 1.9994705765211236,
+		1.9993199934879184, 1.9991505920934984, 1.998962373932334, 1.9987553407760057, 1.9985294945731888, 1.9982848374496338, 1.9980213717081468, 1.9977390998285671,
+		1.9974380244677459, 1.9971181484595186, 1.9967794748146805, 1.9964220067209562, 1.9960457475429725, 1.9956507008222233, 1.995236870277039, 1.9948042598025506,
+		1.9943528734706522, 1.9938827155299643, 1.993393790405792, 1.9928861027000853, 1.9923596571913937, 1.9918144588348234, 1.9912505127619886, 1.9906678242809646,
+		1.9900663988762373, 1.9894462422086514, 1.9888073601153582, 1.9881497586097587, 1.9874734438814492, 1.9867784222961613, 1.9860647003957028, 1.985332284897896,
+		1.9845811826965145, 1.9838114008612187, 1.983022946637488, 1.9822158274465536, 1.9813900508853293, 1.9805456247263382, 1.979682556917641, 1.9788008555827605,
+		1.977900529020606, 1.9769815857053934, 1.976044034286567, 1.9750878835887185, 1.974113142611502, 1.973119820529551, 1.9721079266923909, 1.9710774706243521,
+		1.9700284620244797, 1.9689609107664419, 1.9678748268984378, 1.9667702206431024, 1.9656471023974107, 1.9645054827325787, 1.9633453723939662, 1.9621667823009734,
+		1.9609697235469388, 1.9597542073990353, 1.9585202452981643, 1.9572678488588464, 1.9559970298691143, 1.9547078002904, 1.9534001722574237, 1.9520741580780783,
+		1.950729770233314, 1.9493670213770213, 1.947985924335912, 1.9465864921093963, 1.9451687378694646, 1.9437326749605588, 1.9422783168994502, 1.940805677375111,
+		1.939314770248585, 1.9378056095528577, 1.9362782094927249, 1.934732584444657, 1.9331687489566662, 1.9315867177481674, 1.9299865057098406, 1.9283681279034917,
+		1.926731599561908, 1.9250769360887183, 1.923404153058245, 1.9217132662153593, 1.920004291475332, 1.9182772449236838, 1.9165321428160353, 1.914769001577952,
+		1.9129878378047902, 1.9111886682615422, 1.9093715098826767, 1.9075363797719807, 1.9056832952023974, 1.9038122736158645, 1.9019233326231504, 1.9000164900036862,
+		1.8980917637054011, 1.8961491718445525, 1.8941887327055544, 1.8922104647408067, 1.8902143865705212, 1.8882005169825453, 1.886168874932187, 1.8841194795420348,
+		1.8820523501017785, 1.8799675060680279, 1.877864967064129, 1.8757447528799798, 1.8736068834718431, 1.8714513789621607, 1.8692782596393616, 1.8670875459576726,
+		1.8648792585369247, 1.8626534181623609, 1.860410045784438, 1.8581491625186317, 1.8558707896452358, 1.8535749486091637, 1.8512616610197454, 1.8489309486505252,
+		1.8465828334390553, 1.8442173374866904, 1.8418344830583788, 1.8394342925824545, 1.8370167886504243, 1.8345819940167558, 1.8321299315986634, 1.8296606244758924,
+		1.8271740958905018, 1.8246703692466455, 1.8221494681103527, 1.8196114162093047, 1.8170562374326125, 1.8144839558305916, 1.811894595614537, 1.8092881811564923,
+		1.8066647369890236, 1.8040242878049866, 1.801366858457294, 1.798692473958683, 1.7960011594814798, 1.7932929403573603, 1.7905678420771152, 1.7878258902904065,
+		1.7850671108055294, 1.7822915295891666, 1.7794991727661456, 1.7766900666191927, 1.7738642375886844, 1.7710217122724, 1.76816251742527, 1.7652866799591256,
+		1.7623942269424442, 1.7594851856000948, 1.7565595833130832, 1.7536174476182913, 1.7506588062082218, 1.7476836869307335, 1.7446921177887829, 1.741684126940158,
+		1.7386597426972137, 1.7356189935266066, 1.7325619080490262, 1.7294885150389245, 1.7263988434242483, 1.7232929222861626, 1.7201707808587803, 1.7170324485288855,
+		1.7138779548356573, 1.710707329470392, 1.7075206022762226, 1.7043178032478397, 1.7010989625312067, 1.6978641104232792, 1.6946132773717166, 1.6913464939745981,
+		1.6880637909801328, 1.6847651992863717, 1.6814507499409161, 1.6781204741406255, 1.6747744032313239, 1.6714125687075052, 1.6680350022120363, 1.6646417355358594,
+		1.6612328006176924, 1.65780822954373, 1.6543680545473383, 1.650912308008755, 1.6474410224547826, 1.6439542305584833, 1.6404519651388694, 1.6369342591605973,
+		1.6334011457336557, 1.6298526581130532, 1.6262888296985072, 1.6227096940341275, 1.6191152848081025, 1.6155056358523807, 1.6118807811423526, 1.6082407547965316,
+		1.6045855910762314, 1.6009153243852456, 1.5972299892695219, 1.5935296204168374, 1.5898142526564738, 1.5860839209588873, 1.5823386604353804, 1.5785785063377713,
+		1.5748034940580629, 1.5710136591281079, 1.5672090372192766, 1.563389664142119, 1.559555575846029, 1.5557068084189063, 1.5518433980868154, 1.5479653812136456,
+		1.5440727943007688, 1.5401656739866958, 1.5362440570467308, 1.5323079803926258, 1.5283574810722336, 1.5243925962691578, 1.5204133633024048, 1.5164198196260308,
+		1.512412002828789, 1.5083899506337783, 1.5043537008980854, 1.5003032916124301, 1.4962387609008072, 1.4921601470201276, 1.4880674883598586, 1.4839608234416621,
+		1.4798401909190324, 1.4757056295769322, 1.4715571783314272, 1.4673948762293207, 1.4632187624477853, 1.459028876293994, 1.4548252572047515, 1.4506079447461215,
+		1.446376978613055, 1.4421323986290162, 1.437874244745609, 1.433602557042199, 1.4293173757255382, 1.4250187411293849, 1.4207066937141248, 1.4163812740663906,
+		1.4120425228986797, 1.40769048104897, 1.4033251894803371, 1.3989466892805678, 1.3945550216617733, 1.3901502279600018, 1.3857323496348495, 1.3813014282690694,
+		1.376857505568181, 1.3724006233600774, 1.367930823594631, 1.3634481483432996, 1.3589526397987302, 1.354444340274361, 1.349923292204024, 1.345389538141546,
+		1.3408431207603464, 1.3362840828530371, 1.3317124673310194, 1.3271283172240798, 1.3225316756799845, 1.3179225859640746, 1.313301091458858, 1.308667235663601,
+		1.3040210621939192, 1.2993626147813666, 1.2946919372730243, 1.290009073631088, 1.285314067932454, 1.2806069643683036, 1.2758878072436883, 1.2711566409771125,
+		1.2664135101001146, 1.2616584592568492, 1.2568915332036654, 1.2521127768086873, 1.2473222350513893, 1.2425199530221753, 1.2377059759219529, 1.2328803490617073,
+		1.228043117862077, 1.223194327852924, 1.2183340246729066, 1.2134622540690492, 1.2085790618963121, 1.2036844941171603, 1.1987785968011293, 1.193861416124393,
+		1.188932998369329, 1.183993389924082, 1.1790426372821279, 1.1740807870418362, 1.1691078859060309, 1.164123980681551, 1.1591291182788115, 1.154123345711359,
+		1.1491067100954317, 1.1440792586495143, 1.1390410386938945, 1.1339920976502174, 1.128932483041039, 1.123862242489379, 1.1187814237182723, 1.1136900745503204,
+		1.1085882429072402, 1.103475976809415, 1.0983533243754395, 1.09322033382167, 1.0880770534617679, 1.0829235317062471, 1.0777598170620168, 1.0725859581319264,
+		1.067402003614306, 1.0622080023025102, 1.057004003084457, 1.0517900549421697, 1.0465662069513129, 1.0413325082807345, 1.036089008191999, 1.0308357560389263,
+		1.0255728012671261, 1.0203001934135336, 1.015017982105942, 1.0097262170625352, 1.0044249480914218, 0.9991142250901638, 0.9937940980453094, 0.9884646170319196,
+		0.9831258322131002, 0.9777777938395265, 0.9724205522489732, 0.9670541578658376, 0.9616786612006679, 0.9562941128496862, 0.9509005634943117, 0.9454980639006858,
+		0.9400866649191912, 0.934666417483977, 0.9292373726124756, 0.9237995814049257, 0.9183530950438883, 0.9128979647937677, 0.9074342420003279, 0.9019619780902076,
+		0.89648122457044, 0.8909920330279635, 0.8854944551291403, 0.8799885426192665, 0.8744743473220884, 0.8689519211393114, 0.8634213160501147, 0.8578825841106591,
+		0.8523357774535991, 0.8467809482875922, 0.841218148896805, 0.8356474316404248, 0.8300688489521633, 0.824482453339766, 0.8188882973845152, 0.8132864337407383,
+		0.8076769151353083, 0.8020597943671516, 0.7964351243067472, 0.7908029578956326, 0.785163348145903, 0.7795163481397128, 0.7738620110287774, 0.7682003900338701,
+		0.762531538444325, 0.7568555096175311, 0.7511723569784347, 0.7454821340190316, 0.7397848942978684, 0.7340806914395345, 0.7283695791341597, 0.7226516111369087,
+		0.7169268412674731, 0.7111953234095679, 0.7054571115104215, 0.6997122595802701, 0.6939608216918474, 0.688202851979878, 0.6824384046405648, 0.6766675339310826,
+		0.6708902941690633, 0.6651067397320883, 0.6593169250571751, 0.6535209046402635, 0.6477187330357059, 0.6419104648557504, 0.6360961547700301, 0.6302758575050449,
+		0.6244496278436501, 0.6186175206245375, 0.6127795907417222, 0.6069358931440227, 0.6010864828345468, 0.5952314148701726, 0.5893707443610287, 0.5835045264699787,
+		0.577632816412099, 0.5717556694541615, 0.5658731409141108, 0.5599852861605468, 0.5540921606121999, 0.5481938197374127, 0.5422903190536161, 0.5363817141268062,
+		0.5304680605710238, 0.5245494140478272, 0.5186258302657727, 0.5126973649798858, 0.5067640739911405, 0.5008260131459306, 0.49488323833554687, 0.4889358054956484,
+		0.48298377060573855, 0.477027189688637, 0.4710661188099509, 0.46510061407755066, 0.45913073164103774, 0.4531565276912202, 0.44717805845958, 0.4411953802177473,
+		0.43520854927696734, 0.42921762198757385, 0.4232226547384552, 0.41722370395652686, 0.41122082610619864, 0.4052140776888422, 0.3992035152422621, 0.39318919534016045,
+		0.38717117459160744, 0.38114950964050553, 0.3751242571650595, 0.3690954738772393, 0.36306321652225026, 0.3570275418779951, 0.35098850675454274, 0.34494616799359207,
+		0.33890058246793586, 0.33285180708092843, 0.32679989876594645, 0.3207449144858568, 0.31468691123247655, 0.3086259460260405, 0.3025620759146605, 0.2964953579737924,
+		0.29042584930569504, 0.284353607038896, 0.27827868832765257, 0.27220115035141235, 0.26612105031427835, 0.2600384454444667, 0.25395339299377195, 0.24786595023702437,
+		0.24177617447155444, 0.23568412301665004, 0.2295898532130205, 0.2234934224222533, 0.21739488802627735, 0.2112943074268214, 0.20519173804487253, 0.19908723732013886,
+		0.19298086271050519, 0.18687267169149582, 0.18076272175573002, 0.17465107041238442, 0.16853777518664823, 0.16242289361918477, 0.1563064832655886, 0.15018860169584256,
+		0.14406930649377883, 0.13794865525653346, 0.13182670559400783, 0.12570351512832284, 0.11957914149328001, 0.11345364233381557, 0.10732707530546136, 0.10119949807379867,
+		0.09507096831391852, 0.08894154370987749, 0.08281128195415344, 0.07668024074710558, 0.0705484777964279, 0.06441605081660941, 0.058283017528387486, 0.05214943565820808,
+		0.04601536293767882, 0.0398808571030292, 0.033745975894563546, 0.027610777056120698, 0.02147531833452914, 0.015339657479062154, 0.009203852240897344, 0.003067960372569532,
+	}
+	dct512 = [256]float64{
+		1.9999905876191524, 1.9999152891039278, 1.9997646949084251, 1.9995388107024306, 1.9992376449903573, 1.9988612091109235, 1.9984095172367278, 1.9978825863737137,
+		1.9972804363605305, 1.9966030898677858, 1.995850572397192, 1.995022912280607, 1.994120140678966, 1.99314229158111, 1.992089401802504, 1.9909615109838539,
+		1.9897586615896112, 1.988480898906376, 1.9871282710411906, 1.98570082891973, 1.9841986262843836, 1.9826217196922309, 1.9809701685129142, 1.9792440349264018,
+		1.9774433839206476, 1.9755682832891444, 1.973618803628371, 1.9715950183351347, 1.9694970036038084, 1.9673248384234605, 1.9650786045748825, 1.9627583866275091,
+		1.9603642719362346, 1.9578963506381244, 1.9553547156490199, 1.9527394626600423, 1.9500506901339882, 1.9472884993016237, 1.9444529941578725, 1.9415442814579005,
+		1.938562470713097, 1.935507674186951, 1.932380006890825, 1.9291795865796255, 1.9259065337473678, 1.9225609716226413, 1.919143026163969, 1.9156528260550656,
+		1.9120905026999928, 1.9084561902182113, 1.9047500254395318, 1.9009721478989634, 1.8971226998314605, 1.8932018261665673, 1.8892096745229605, 1.8851463952028937,
+		1.8810121411865368, 1.8768070681262161, 1.8725313343405565, 1.868185100808518, 1.8637685311633363, 1.8592817916863624, 1.8547250513008022, 1.8500984815653552,
+		1.845402256667757, 1.8406365534182212, 1.835801551242781, 1.8308974321765357, 1.8259243808567964, 1.8208825845161343, 1.8157722329753325, 1.8105935186362374,
+		1.8053466364745177, 1.8000317840323203, 1.7946491614108366, 1.7891989712627654, 1.7836814187846854, 1.7780967117093291, 1.7724450602977615, 1.7667266773314632,
+		1.7609417781043215, 1.7550905804145227, 1.7491733045563522, 1.743190173311902, 1.737141411942682, 1.7310272481811382, 1.724847912222081, 1.718603636714017,
+		1.712294656750389, 1.7059212098607273, 1.6994835360017049, 1.6929818775481043, 1.6864164792836909, 1.679787588391999, 1.6730954544470238, 1.6663403294038264,
+		1.659522467589046, 1.6526421256913268, 1.6456995627516529, 1.638695040153594, 1.6316288216134676, 1.624501173170408, 1.61731236317635, 1.6100626622859273,
+		1.6027523434462805, 1.5953816818867823, 1.5879509551086743, 1.58046044287462, 1.5729104271981718, 1.5653011923331515, 1.557633024762952, 1.5499062131897479,
+		1.5421210485236276, 1.534277823871641, 1.5263768345267625, 1.518418377956776, 1.5104027537930729, 1.5023302638193727, 1.4942012119603603, 1.4860159042702434,
+		1.4777746489212302, 1.469477756191927, 1.4611255384556552, 1.452718310168692, 1.4442563878584307, 1.4357400901114636, 1.427169737561587, 1.4185456528777314,
+		1.40986816075181, 1.401137587886497, 1.3923542629829262, 1.3835185167283157, 1.3746306817835183, 1.3656910927704962, 1.3567000862597232, 1.3476580007575123,
+		1.3385651766932722, 1.3294219564066898, 1.3202286841348412, 1.310985705999231, 1.301693369992762, 1.2923520259666328, 1.2829620256171663, 1.2735237224725686,
+		1.2640374718796181, 1.2545036309902884, 1.2449225587483002, 1.235294615875608, 1.2256201648588196, 1.2158995699355475, 1.2061331970806966, 1.1963214139926848,
+		1.1864645900795998, 1.1765630964452907, 1.1666173058753968, 1.1566275928233114, 1.1465943333960846, 1.1365179053402632, 1.1263986880276684, 1.1162370624411124,
+		1.1060334111600552, 1.0957881183462006, 1.085501569729032, 1.075174152591291, 1.064806255754396, 1.0543982695638028, 1.0439505858743088, 1.0334635980353,
+		1.022937700875941, 1.012373290690311, 1.0017707652224819, 0.9911305236515451, 0.9804529665765823, 0.9697384960015822, 0.9589875153203061, 0.9482004293011,
+		0.9373776440716559, 0.9265195671037205, 0.9156266071977546, 0.904699174467542, 0.8937376803247487, 0.8827425374634332, 0.871714159844511, 0.8606529626801652,
+		0.8495593624182176, 0.8384337767264479, 0.8272766244768691, 0.8160883257299575, 0.8048693017188371, 0.7936199748334208, 0.782340768604508, 0.771032107687838,
+		0.7596944178481022, 0.7483281259429159, 0.7369336599067446, 0.7255114487347945, 0.7140619224668601, 0.7025855121711343, 0.6910826499279783, 0.6795537688136539,
+		0.667999302884019, 0.6564196871581853, 0.6448153576021399, 0.6331867511123317, 0.621534305499223, 0.6098584594708047, 0.598159652616081, 0.5864383253885174,
+		0.5746949190894591, 0.5629298758515161, 0.5511436386219165, 0.5393366511458304, 0.527509357949663, 0.5156622043243179, 0.5037956363084338, 0.4919101006715892,
+		0.480006044897483, 0.4680839171670869, 0.4561441663417716, 0.4441872419464072, 0.4322135941524392, 0.42022367376093944, 0.408217932185634, 0.39619682143590745,
+		0.38416079409978476, 0.3721103033268932, 0.36004580281139903, 0.3479677467749277, 0.3358765899494624, 0.32377278756022376, 0.31165679530853063, 0.29952906935464324,
+		0.28739006630058916, 0.27524024317297235, 0.2630800574057665, 0.2509099668230924, 0.23873042962198268, 0.2265419043551287, 0.21434484991361774, 0.20213972550965573,
+		0.18992699065927812, 0.17770710516504937, 0.1654805290987516, 0.15324772278406323, 0.14100914677922802, 0.12876526185971482, 0.11651652900087146, 0.10426340936056663,
+		0.09200636426182929, 0.07974585517547969, 0.06748234370275528, 0.05521629155793164, 0.04294816055093922, 0.03067841256997644, 0.01840750956411992, 0.006135913525932276,
+	}
 	dct256 = [128]float64{
 		1.9999623505652022, 1.9996611635916468, 1.9990588350021863, 1.9981554555052907, 1.9969511611465895, 1.9954461332883833, 1.9936405985823316, 1.9915348289353196,
 		1.9891291414685108, 1.986423898469589, 1.983419507338199, 1.9801164205245942, 1.976515135461499, 1.9726161944891973, 1.968420184773858, 1.9639277382191105,
@@ -184,4 +339,13 @@ var (
 	dct16 = [8]float64{
 		1.9903694533443936, 1.9138806714644176, 1.76384252869671, 1.546020906725474, 1.2687865683272912, 0.9427934736519956, 0.5805693545089246, 0.19603428065912154,
 	}
+	dct8 = [4]float64{
+		1.9615705608064609, 1.6629392246050907, 1.1111404660392046, 0.3901806440322566,
+	}
+	dct4 = [2]float64{
+		1.8477590650225735, 0.7653668647301797,
+	}
+	dct2 = [1]float64{
+		1.4142135623730951,
+	}
 )
@@ -0,0 +1,351 @@
+// Code generated by internal/gen; DO NOT EDIT.
+
+package dct
+
+// DCT type II, unscaled. Algorithm by Byeong Gi Lee, 1984.
+// Static implementation by Evan Oberholster, 2022.
+
+func transformDCT1024F32(input []float32) {
+	var temp [1024]float32
+	for i := 0; i < 512; i++ {
+		x, y := input[i], input[1024-1-i]
+		temp[i] = x + y
+		temp[i+512] = (x - y) / dct1024F32[i]
+	}
+	transformDCT512F32(temp[:512])
+	transformDCT512F32(temp[512:])
+	for i := 0; i < 512-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+512] + temp[i+512+1]
+	}
+	input[1024-2], input[1024-1] = temp[512-1], temp[1024-1]
+}
+
+func transformDCT512F32(input []float32) {
+	var temp [512]float32
+	for i := 0; i < 256; i++ {
+		x, y := input[i], input[512-1-i]
+		temp[i] = x + y
+		temp[i+256] = (x - y) / dct512F32[i]
+	}
+	transformDCT256F32(temp[:256])
+	transformDCT256F32(temp[256:])
+	for i := 0; i < 256-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+256] + temp[i+256+1]
+	}
+	input[512-2], input[512-1] = temp[256-1], temp[512-1]
+}
+
+func transformDCT256F32(input []float32) {
+	var temp [256]float32
+	for i := 0; i < 128; i++ {
+		x, y := input[i], input[256-1-i]
+		temp[i] = x + y
+		temp[i+128] = (x - y) / dct256F32[i]
+	}
+	transformDCT128F32(temp[:128])
+	transformDCT128F32(temp[128:])
+	for i := 0; i < 128-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+128] + temp[i+128+1]
+	}
+	input[256-2], input[256-1] = temp[128-1], temp[256-1]
+}
+
+func transformDCT128F32(input []float32) {
+	var temp [128]float32
+	for i := 0; i < 64; i++ {
+		x, y := input[i], input[128-1-i]
+		temp[i] = x + y
+		temp[i+64] = (x - y) / dct128F32[i]
+	}
+	transformDCT64F32(temp[:64])
+	transformDCT64F32(temp[64:])
+	for i := 0; i < 64-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+64] + temp[i+64+1]
+	}
+	input[128-2], input[128-1] = temp[64-1], temp[128-1]
+}
+
+func transformDCT64F32(input []float32) {
+	var temp [64]float32
+	for i := 0; i < 32; i++ {
+		x, y := input[i], input[64-1-i]
+		temp[i] = x + y
+		temp[i+32] = (x - y) / dct64F32[i]
+	}
+	transformDCT32F32(temp[:32])
+	transformDCT32F32(temp[32:])
+	for i := 0; i < 32-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+32] + temp[i+32+1]
+	}
+	input[64-2], input[64-1] = temp[32-1], temp[64-1]
+}
+
+func transformDCT32F32(input []float32) {
+	var temp [32]float32
+	for i := 0; i < 16; i++ {
+		x, y := input[i], input[32-1-i]
+		temp[i] = x + y
+		temp[i+16] = (x - y) / dct32F32[i]
+	}
+	transformDCT16F32(temp[:16])
+	transformDCT16F32(temp[16:])
+	for i := 0; i < 16-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+16] + temp[i+16+1]
+	}
+	input[32-2], input[32-1] = temp[16-1], temp[32-1]
+}
+
+func transformDCT16F32(input []float32) {
+	var temp [16]float32
+	for i := 0; i < 8; i++ {
+		x, y := input[i], input[16-1-i]
+		temp[i] = x + y
+		temp[i+8] = (x - y) / dct16F32[i]
+	}
+	transformDCT8F32(temp[:8])
+	transformDCT8F32(temp[8:])
+	for i := 0; i < 8-1; i++ {
+		input[i*2+0] = temp[i]
+		input[i*2+1] = temp[i+8] + temp[i+8+1]
+	}
+	input[16-2], input[16-1] = temp[8-1], temp[16-1]
+}
+
+func transformDCT8F32(input []float32) {
+	a, b := [4]float32{}, [4]float32{}
+
+	x0, y0 := input[0], input[7]
+	x1, y1 := input[1], input[6]
+	x2, y2 := input[2], input[5]
+	x3, y3 := input[3], input[4]
+
+	a[0] = x0 + y0
+	a[1] = x1 + y1
+	a[2] = x2 + y2
+	a[3] = x3 + y3
+	b[0] = (x0 - y0) / dct8F32[0]
+	b[1] = (x1 - y1) / dct8F32[1]
+	b[2] = (x2 - y2) / dct8F32[2]
+	b[3] = (x3 - y3) / dct8F32[3]
+
+	transformDCT4F32(a[:])
+	transformDCT4F32(b[:])
+
+	input[0] = a[0]
+	input[1] = b[0] + b[1]
+	input[2] = a[1]
+	input[3] = b[1] + b[2]
+	input[4] = a[2]
+	input[5] = b[2] + b[3]
+	input[6] = a[3]
+	input[7] = b[3]
+}
+
+func transformDCT4F32(input []float32) {
+	x0, y0 := input[0], input[3]
+	x1, y1 := input[1], input[2]
+
+	t0 := x0 + y0
+	t1 := x1 + y1
+	t2 := (x0 - y0) / dct4F32[0]
+	t3 := (x1 - y1) / dct4F32[1]
+
+	x, y := t0, t1
+	t0 += t1
+	t1 = (x - y) / dct2F32[0]
+
+	x, y = t2, t3
+	t2 += t3
+	t3 = (x - y) / dct2F32[0]
+
+	input[0] = t0
+	input[1] = t2 + t3
+	input[2] = t1
+	input[3] = t3
+}
+
+// static_dct_kernel_f32 returns the kernel for sizes with a static table, or nil.
+func static_dct_kernel_f32(n int) func([]float32) {
+	switch n {
+	case 4:
+		return transformDCT4F32
+	case 8:
+		return fct8_1d[float32]
+	case 16:
+		return transformDCT16F32
+	case 32:
+		return transformDCT32F32
+	case 64:
+		return transformDCT64F32
+	case 128:
+		return transformDCT128F32
+	case 256:
+		return transformDCT256F32
+	case 512:
+		return transformDCT512F32
+	case 1024:
+		return transformDCT1024F32
+	}
+	return nil
+}
+
+// Static DCT Tables, dct{N}[i] = 2 cos((i+0.5) pi / N), the divisors of
+// every level of the recursion.
+var (
+	dct1024F32 = [512]float32{
+		1.9999976, 1.9999788, 1.9999412, 1.9998847, 1.9998094, 1.9997153, 1.9996023, 1.9994706,
+		1.99932, 1.9991506, 1.9989624, 1.9987553, 1.9985296, 1.9982848, 1.9980214, 1.9977391,
+		1.9974381, 1.9971181, 1.9967794, 1.996422, 1.9960457, 1.9956506, 1.9952369, 1.9948043,
+		1.9943528, 1.9938827, 1.9933938, 1.9928861, 1.9923596, 1.9918145, 1.9912505, 1.9906678,
+		1.9900664, 1.9894463, 1.9888073, 1.9881498, 1.9874735, 1.9867784, 1.9860647, 1.9853323,
+		1.9845812, 1.9838114, 1.9830229, 1.9822159, 1.98139, 1.9805456, 1.9796826, 1.9788009,
+		1.9779005, 1.9769816, 1.976044, 1.9750879, 1.9741131, 1.9731199, 1.9721079, 1.9710774,
+		1.9700285, 1.9689609, 1.9678749, 1.9667702, 1.9656471, 1.9645054, 1.9633454, 1.9621668,
+		1.9609697, 1.9597542, 1.9585203, 1.9572679, 1.955997, 1.9547077, 1.9534001, 1.9520742,
+		1.9507297, 1.949367, 1.9479859, 1.9465865, 1.9451687, 1.9437326, 1.9422783, 1.9408057,
+		1.9393147, 1.9378057, 1.9362782, 1.9347326, 1.9331688, 1.9315867, 1.9299865, 1.9283681,
+		1.9267316, 1.925077, 1.9234041, 1.9217132, 1.9200042, 1.9182773, 1.9165322, 1.914769,
+		1.9129878, 1.9111887, 1.9093715, 1.9075364, 1.9056833, 1.9038123, 1.9019233, 1.9000165,
+		1.8980918, 1.8961492, 1.8941888, 1.8922105, 1.8902144, 1.8882005, 1.8861688, 1.8841195,
+		1.8820523, 1.8799675, 1.877865, 1.8757447, 1.8736069, 1.8714514, 1.8692783, 1.8670876,
+		1.8648793, 1.8626534, 1.8604101, 1.8581492, 1.8558708, 1.853575, 1.8512616, 1.848931,
+		1.8465829, 1.8442173, 1.8418344, 1.8394343, 1.8370168, 1.834582, 1.83213, 1.8296607,
+		1.8271741, 1.8246703, 1.8221495, 1.8196114, 1.8170562, 1.814484, 1.8118945, 1.8092881,
+		1.8066647, 1.8040243, 1.8013668, 1.7986925, 1.7960012, 1.793293, 1.7905679, 1.787826,
+		1.7850671, 1.7822915, 1.7794992, 1.7766901, 1.7738643, 1.7710217, 1.7681625, 1.7652867,
+		1.7623942, 1.7594852, 1.7565596, 1.7536174, 1.7506588, 1.7476836, 1.7446921, 1.7416841,
+		1.7386597, 1.735619, 1.732562, 1.7294885, 1.7263988, 1.723293, 1.7201707, 1.7170324,
+		1.7138779, 1.7107073, 1.7075206, 1.7043178, 1.7010989, 1.697864, 1.6946132, 1.6913465,
+		1.6880637, 1.6847652, 1.6814507, 1.6781205, 1.6747744, 1.6714126, 1.668035, 1.6646417,
+		1.6612328, 1.6578082, 1.654368, 1.6509123, 1.647441, 1.6439543, 1.6404519, 1.6369343,
+		1.6334012, 1.6298527, 1.6262888, 1.6227098, 1.6191152, 1.6155057, 1.6118808, 1.6082407,
+		1.6045856, 1.6009153, 1.59723, 1.5935296, 1.5898143, 1.5860839, 1.5823387, 1.5785785,
+		1.5748035, 1.5710137, 1.567209, 1.5633897, 1.5595555, 1.5557069, 1.5518434, 1.5479654,
+		1.5440727, 1.5401657, 1.536244, 1.532308, 1.5283575, 1.5243926, 1.5204134, 1.5164198,
+		1.512412, 1.50839, 1.5043536, 1.5003033, 1.4962387, 1.4921602, 1.4880675, 1.4839609,
+		1.4798402, 1.4757056, 1.4715571, 1.4673948, 1.4632188, 1.4590288, 1.4548253, 1.4506079,
+		1.4463769, 1.4421324, 1.4378742, 1.4336026, 1.4293174, 1.4250188, 1.4207067, 1.4163812,
+		1.4120425, 1.4076905, 1.4033252, 1.3989466, 1.394555, 1.3901502, 1.3857323, 1.3813014,
+		1.3768575, 1.3724006, 1.3679308, 1.3634481, 1.3589526, 1.3544444, 1.3499233, 1.3453895,
+		1.3408431, 1.336284, 1.3317125, 1.3271283, 1.3225317, 1.3179226, 1.3133011, 1.3086672,
+		1.3040211, 1.2993627, 1.2946919, 1.290009, 1.2853141, 1.280607, 1.2758878, 1.2711567,
+		1.2664136, 1.2616584, 1.2568915, 1.2521127, 1.2473222, 1.24252, 1.237706, 1.2328804,
+		1.2280431, 1.2231944, 1.2183341, 1.2134622, 1.2085791, 1.2036844, 1.1987786, 1.1938614,
+		1.188933, 1.1839933, 1.1790426, 1.1740807, 1.1691079, 1.164124, 1.1591291, 1.1541233,
+		1.1491067, 1.1440792, 1.1390411, 1.1339921, 1.1289325, 1.1238623, 1.1187814, 1.11369,
+		1.1085882, 1.1034759, 1.0983533, 1.0932204, 1.0880771, 1.0829235, 1.0777599, 1.0725859,
+		1.067402, 1.062208, 1.057004, 1.05179, 1.0465662, 1.0413325, 1.0360891, 1.0308357,
+		1.0255728, 1.0203001, 1.015018, 1.0097262, 1.0044249, 0.9991142, 0.9937941, 0.9884646,
+		0.9831258, 0.9777778, 0.9724206, 0.9670541, 0.9616787, 0.9562941, 0.95090055, 0.94549805,
+		0.94008666, 0.9346664, 0.92923737, 0.9237996, 0.9183531, 0.91289794, 0.9074342, 0.901962,
+		0.8964812, 0.89099205, 0.8854945, 0.87998855, 0.87447435, 0.8689519, 0.8634213, 0.85788256,
+		0.85233575, 0.84678096, 0.8412182, 0.8356474, 0.8300688, 0.82448244, 0.8188883, 0.8132864,
+		0.8076769, 0.80205977, 0.7964351, 0.79080296, 0.78516334, 0.77951634, 0.773862, 0.7682004,
+		0.7625315, 0.7568555, 0.75117236, 0.74548215, 0.7397849, 0.7340807, 0.7283696, 0.7226516,
+		0.7169268, 0.71119535, 0.7054571, 0.6997123, 0.69396085, 0.68820286, 0.68243843, 0.6766675,
+		0.6708903, 0.6651067, 0.6593169, 0.6535209, 0.6477187, 0.6419105, 0.6360962, 0.63027585,
+		0.6244496, 0.61861753, 0.6127796, 0.6069359, 0.6010865, 0.5952314, 0.5893707, 0.5835045,
+		0.57763284, 0.57175565, 0.56587315, 0.5599853, 0.55409217, 0.5481938, 0.54229033, 0.5363817,
+		0.53046805, 0.5245494, 0.51862586, 0.51269734, 0.50676405, 0.500826, 0.49488324, 0.4889358,
+		0.48298377, 0.47702718, 0.47106612, 0.46510062, 0.45913073, 0.45315653, 0.44717807, 0.44119537,
+		0.43520856, 0.42921764, 0.42322266, 0.4172237, 0.41122082, 0.40521407, 0.3992035, 0.3931892,
+		0.38717118, 0.3811495, 0.37512425, 0.36909547, 0.36306322, 0.35702753, 0.3509885, 0.34494618,
+		0.3389006, 0.3328518, 0.3267999, 0.3207449, 0.31468692, 0.30862594, 0.3025621, 0.29649535,
+		0.29042584, 0.2843536, 0.27827868, 0.27220115, 0.26612106, 0.26003844, 0.2539534, 0.24786595,
+		0.24177617, 0.23568413, 0.22958985, 0.22349343, 0.21739489, 0.21129431, 0.20519173, 0.19908723,
+		0.19298086, 0.18687268, 0.18076272, 0.17465107, 0.16853778, 0.1624229, 0.15630649, 0.1501886,
+		0.1440693, 0.13794866, 0.1318267, 0.12570351, 0.119579144, 0.11345364, 0.107327074, 0.1011995,
+		0.095070966, 0.088941544, 0.08281128, 0.07668024, 0.070548475, 0.06441605, 0.058283016, 0.052149437,
+		0.046015363, 0.039880857, 0.033745974, 0.027610777, 0.021475319, 0.015339658, 0.009203852, 0.0030679603,
+	}
+	dct512F32 = [256]float32{
+		1.9999906, 1.9999152, 1.9997647, 1.9995388, 1.9992377, 1.9988612, 1.9984095, 1.9978826,
+		1.9972805, 1.9966031, 1.9958506, 1.9950229, 1.9941201, 1.9931422, 1.9920894, 1.9909616,
+		1.9897586, 1.9884809, 1.9871283, 1.9857008, 1.9841986, 1.9826217, 1.9809701, 1.979244,
+		1.9774433, 1.9755683, 1.9736187, 1.971595, 1.969497, 1.9673249, 1.9650786, 1.9627584,
+		1.9603642, 1.9578964, 1.9553547, 1.9527395, 1.9500507, 1.9472885, 1.944453, 1.9415443,
+		1.9385625, 1.9355077, 1.93238, 1.9291795, 1.9259065, 1.9225609, 1.9191431, 1.9156529,
+		1.9120905, 1.9084562, 1.90475, 1.9009721, 1.8971227, 1.8932018, 1.8892096, 1.8851464,
+		1.8810121, 1.8768071, 1.8725313, 1.868185, 1.8637686, 1.8592818, 1.854725, 1.8500985,
+		1.8454022, 1.8406366, 1.8358016, 1.8308975, 1.8259244, 1.8208826, 1.8157722, 1.8105935,
+		1.8053466, 1.8000318, 1.7946491, 1.789199, 1.7836814, 1.7780967, 1.7724451, 1.7667267,
+		1.7609417, 1.7550906, 1.7491733, 1.7431902, 1.7371414, 1.7310272, 1.7248479, 1.7186036,
+		1.7122947, 1.7059212, 1.6994835, 1.6929818, 1.6864165, 1.6797876, 1.6730955, 1.6663404,
+		1.6595224, 1.6526421, 1.6456996, 1.638695, 1.6316289, 1.6245012, 1.6173123, 1.6100627,
+		1.6027523, 1.5953817, 1.587951, 1.5804604, 1.5729104, 1.5653012, 1.557633, 1.5499063,
+		1.542121, 1.5342778, 1.5263768, 1.5184184, 1.5104028, 1.5023303, 1.4942012, 1.4860159,
+		1.4777746, 1.4694778, 1.4611255, 1.4527183, 1.4442564, 1.4357401, 1.4271697, 1.4185456,
+		1.4098681, 1.4011376, 1.3923542, 1.3835185, 1.3746307, 1.3656911, 1.3567001, 1.347658,
+		1.3385652, 1.329422, 1.3202287, 1.3109857, 1.3016933, 1.2923521, 1.2829621, 1.2735237,
+		1.2640375, 1.2545036, 1.2449225, 1.2352946, 1.2256202, 1.2158996, 1.2061332, 1.1963214,
+		1.1864645, 1.1765631, 1.1666173, 1.1566275, 1.1465943, 1.1365179, 1.1263987, 1.116237,
+		1.1060334, 1.0957881, 1.0855016, 1.0751741, 1.0648062, 1.0543983, 1.0439506, 1.0334636,
+		1.0229377, 1.0123733, 1.0017707, 0.99113053, 0.98045295, 0.9697385, 0.95898753, 0.9482004,
+		0.93737763, 0.9265196, 0.9156266, 0.90469915, 0.8937377, 0.8827425, 0.8717142, 0.860653,
+		0.84955937, 0.8384338, 0.82727665, 0.8160883, 0.8048693, 0.79362, 0.78234076, 0.7710321,
+		0.7596944, 0.74832815, 0.73693365, 0.72551143, 0.7140619, 0.7025855, 0.69108266, 0.67955375,
+		0.6679993, 0.6564197, 0.6448154, 0.63318676, 0.6215343, 0.60985845, 0.5981597, 0.5864383,
+		0.57469493, 0.56292987, 0.55114365, 0.5393366, 0.52750933, 0.5156622, 0.5037956, 0.4919101,
+		0.48000604, 0.46808392, 0.45614415, 0.44418725, 0.4322136, 0.42022368, 0.40821794, 0.3961968,
+		0.3841608, 0.3721103, 0.3600458, 0.34796774, 0.33587658, 0.3237728, 0.3116568, 0.29952908,
+		0.28739005, 0.27524024, 0.26308006, 0.25090995, 0.23873043, 0.2265419, 0.21434484, 0.20213972,
+		0.189927, 0.1777071, 0.16548052, 0.15324773, 0.14100915, 0.12876526, 0.11651653, 0.10426341,
+		0.09200636, 0.07974585, 0.067482345, 0.05521629, 0.04294816, 0.030678412, 0.018407509, 0.0061359135,
+	}
+	dct256F32 = [128]float32{
+		1.9999623, 1.9996612, 1.9990588, 1.9981555, 1.9969511, 1.9954461, 1.9936405, 1.9915348,
+		1.9891292, 1.9864239, 1.9834195, 1.9801164, 1.9765152, 1.9726162, 1.9684201, 1.9639277,
+		1.9591396, 1.9540563, 1.9486787, 1.9430078, 1.9370441, 1.9307889, 1.9242429, 1.9174069,
+		1.9102824, 1.90287, 1.8951712, 1.8871869, 1.8789184, 1.870367, 1.8615339, 1.8524204,
+		1.8430281, 1.8333582, 1.8234121, 1.8131914, 1.8026977, 1.7919325, 1.7808975, 1.7695942,
+		1.7580245, 1.74619, 1.7340925, 1.7217339, 1.709116, 1.6962407, 1.68311, 1.6697258,
+		1.6560901, 1.642205, 1.6280726, 1.6136951, 1.5990745, 1.5842131, 1.5691131, 1.553777,
+		1.5382067, 1.5224048, 1.5063736, 1.4901155, 1.4736332, 1.4569287, 1.4400051, 1.4228644,
+		1.4055095, 1.3879429, 1.3701674, 1.3521854, 1.3339999, 1.3156134, 1.2970288, 1.2782489,
+		1.2592765, 1.2401145, 1.2207656, 1.2012329, 1.1815194, 1.1616279, 1.1415615, 1.1213231,
+		1.1009159, 1.0803429, 1.0596073, 1.038712, 1.0176603, 0.9964553, 0.97510034, 0.95359844,
+		0.931953, 0.91016716, 0.8882443, 0.86618763, 0.8440005, 0.8216863, 0.7992484, 0.77669007,
+		0.75401485, 0.73122597, 0.70832705, 0.68532145, 0.6622126, 0.63900405, 0.6156993, 0.5923018,
+		0.56881505, 0.5452427, 0.5215882, 0.49785522, 0.4740472, 0.45016783, 0.42622063, 0.40220928,
+		0.37813732, 0.35400844, 0.32982624, 0.30559438, 0.2813165, 0.2569962, 0.23263726, 0.20824327,
+		0.18381791, 0.15936488, 0.13488784, 0.11039049, 0.08587652, 0.061349608, 0.03681346, 0.012271769,
+	}
+	dct128F32 = [64]float32{
+		1.9998494, 1.9986447, 1.9962362, 1.9926252, 1.987814, 1.9818053, 1.9746028, 1.966211,
+		1.9566348, 1.9458799, 1.9339529, 1.920861, 1.906612, 1.8912146, 1.874678, 1.8570122,
+		1.8382277, 1.818336, 1.797349, 1.7752793, 1.7521402, 1.7279457, 1.7027104, 1.6764494,
+		1.6491786, 1.6209143, 1.5916739, 1.5614744, 1.5303345, 1.4982728, 1.4653085, 1.4314617,
+		1.3967525, 1.361202, 1.3248316, 1.2876631, 1.249719, 1.2110221, 1.1715957, 1.1314636,
+		1.09065, 1.0491793, 1.0070767, 0.96436757, 0.92107743, 0.8772325, 0.8328591, 0.7879841,
+		0.7426344, 0.69683737, 0.6506206, 0.6040119, 0.5570394, 0.5097313, 0.4621162, 0.41422275,
+		0.36607978, 0.3177163, 0.2691614, 0.22044441, 0.17159462, 0.122641474, 0.07361445, 0.024543077,
+	}
+	dct64F32 = [32]float32{
+		1.9993976, 1.9945809, 1.9849591, 1.9705553, 1.9514042, 1.9275521, 1.8990563, 1.8659856,
+		1.8284196, 1.7864486, 1.7401739, 1.6897072, 1.6351696, 1.5766928, 1.5144176, 1.4484942,
+		1.3790811, 1.3063457, 1.2304631, 1.1516163, 1.0699953, 0.9857964, 0.8992227, 0.8104826,
+		0.7197901, 0.6273635, 0.5334255, 0.43820247, 0.34192377, 0.24482135, 0.14712913, 0.049082458,
+	}
+	dct32F32 = [16]float32{
+		1.9975909, 1.978353, 1.9400625, 1.8830881, 1.8079786, 1.7154572, 1.606415, 1.4819022,
+		1.343118, 1.1913986, 1.0282055, 0.85511017, 0.6737797, 0.48596036, 0.29346094, 0.09813535,
+	}
+	dct16F32 = [8]float32{
+		1.9903694, 1.9138807, 1.7638426, 1.5460209, 1.2687865, 0.9427935, 0.5805693, 0.19603428,
+	}
+	dct8F32 = [4]float32{
+		1.9615705, 1.6629392, 1.1111405, 0.39018065,
+	}
+	dct4F32 = [2]float32{
+		1.847759, 0.76536685,
+	}
+	dct2F32 = [1]float32{
+		1.4142135,
+	}
+)
@@ -0,0 +1,369 @@
+package dct
+
+import (
+	"math"
+	"slices"
+)
+
+// DCTI_1D returns the DCT-I of input,
+// X[k] = (x[0] + (-1)^k x[N-1])/2 + sum x[n] cos(pi/(N-1) n k) for 0 < n < N-1.
+// It is its own inverse up to a factor of 2/(N-1). sz-1 a power of 2 takes the
+// fast path. It returns ErrInvalidInput unless sz is at least 2 and the length
+// of input.
+func DCTI_1D(input []float64, sz int) ([]float64, error) {
+	return variant_1d(input, sz, 2, dct1_kernel[float64])
+}
+
+// DCTIV_1D returns the DCT-IV of input,
+// X[k] = sum x[n] cos(pi/N (n+0.5) (k+0.5)), the building block of the MDCT.
+// It is its own inverse up to a factor of 2/N. It returns ErrInvalidInput
+// unless sz is positive and the length of input, as do the DST variants.
+func DCTIV_1D(input []float64, sz int) ([]float64, error) {
+	return variant_1d(input, sz, 1, dct4_kernel[float64])
+}
+
+// DSTI_1D returns the DST-I of input, X[k] = sum x[n] sin(pi/(N+1) (n+1) (k+1)).
+// It is its own inverse up to a factor of 2/(N+1). sz+1 a power of 2 takes
+// the fast path.
+func DSTI_1D(input []float64, sz int) ([]float64, error) {
+	return variant_1d(input, sz, 1, dst1_kernel[float64])
+}
+
+// DSTII_1D returns the DST-II of input, X[k] = sum x[n] sin(pi/N (n+0.5) (k+1)).
+// DSTIII_1D scaled by 2/N is its inverse.
+func DSTII_1D(input []float64, sz int) ([]float64, error) {
+	return variant_1d(input, sz, 1, dst2_kernel[float64])
+}
+
+// DSTIII_1D returns the DST-III of input,
+// X[k] = (-1)^k x[N-1]/2 + sum x[n] sin(pi/N (n+1) (k+0.5)) for n < N-1.
+func DSTIII_1D(input []float64, sz int) ([]float64, error) {
+	return variant_1d(input, sz, 1, dst3_kernel[float64])
+}
+
+// DSTIV_1D returns the DST-IV of input, X[k] = sum x[n] sin(pi/N (n+0.5) (k+0.5)).
+// It is its own inverse up to a factor of 2/N.
+func DSTIV_1D(input []float64, sz int) ([]float64, error) {
+	return variant_1d(input, sz, 1, dst4_kernel[float64])
+}
+
+// DCTIMatrix replaces m with its DCT-I in place, along both axes, or along the
+// row for a single row, as DCTMatrix does for the DCT-II. Each axis must be at
+// least 2 long.
+func DCTIMatrix(m Matrix) error {
+	return variant_matrix(m, 2, dct1_kernel[float64])
+}
+
+// DCTIVMatrix is DCTIMatrix for the DCT-IV.
+func DCTIVMatrix(m Matrix) error {
+	return variant_matrix(m, 1, dct4_kernel[float64])
+}
+
+// DSTIMatrix is DCTIMatrix for the DST-I.
+func DSTIMatrix(m Matrix) error {
+	return variant_matrix(m, 1, dst1_kernel[float64])
+}
+
+// DSTIIMatrix is DCTIMatrix for the DST-II.
+func DSTIIMatrix(m Matrix) error {
+	return variant_matrix(m, 1, dst2_kernel[float64])
+}
+
+// DSTIIIMatrix is DCTIMatrix for the DST-III.
+func DSTIIIMatrix(m Matrix) error {
+	return variant_matrix(m, 1, dst3_kernel[float64])
+}
+
+// DSTIVMatrix is DCTIMatrix for the DST-IV.
+func DSTIVMatrix(m Matrix) error {
+	return variant_matrix(m, 1, dst4_kernel[float64])
+}
+
+// variant_1d returns the transform of input by the kernel of new_kernel, or
+// ErrInvalidInput if sz is below min_size or not the length of input.
+func variant_1d(input []float64, sz, min_size int, new_kernel func(int) func([]float64)) ([]float64, error) {
+	if sz < min_size || len(input) != sz {
+		return nil, ErrInvalidInput
+	}
+
+	result := slices.Clone(input)
+	new_kernel(sz)(result)
+	return result, nil
+}
+
+// variant_matrix transforms m in place by the kernels of new_kernel, or
+// returns ErrInvalidInput if an axis to transform is below min_size.
+func variant_matrix(m Matrix, min_size int, new_kernel func(int) func([]float64)) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	if m.Cols < min_size || (m.Rows > 1 && m.Rows < min_size) {
+		return ErrInvalidInput
+	}
+
+	if m.Rows == 1 {
+		new_kernel(m.Cols)(m.Row(0))
+		return nil
+	}
+
+	separable_2d_strided(m.Data, m.Rows, m.Cols, m.Stride, new_kernel(m.Cols), new_kernel(m.Rows))
+	return nil
+}
+
+// Kernels of the variants for size n, the fast transform where n allows it and
+// the naive one otherwise.
+
+func dct1_kernel[T Float](n int) func([]T) {
+	if m := n - 1; m > 0 && (m&(m-1)) == 0 {
+		return func(v []T) { fast_dct1_1d(v, n) }
+	}
+	return func(v []T) { dct1_1d(v, n) }
+}
+
+func dct4_kernel[T Float](n int) func([]T) {
+	if (n & (n - 1)) == 0 {
+		return func(v []T) { fast_dct4_1d(v, n) }
+	}
+	return func(v []T) { dct4_1d(v, n) }
+}
+
+func dst1_kernel[T Float](n int) func([]T) {
+	if m := n + 1; (m & (m - 1)) == 0 {
+		return func(v []T) { fast_dst1_1d(v, n) }
+	}
+	return func(v []T) { dst1_1d(v, n) }
+}
+
+func dst2_kernel[T Float](n int) func([]T) {
+	if (n & (n - 1)) == 0 {
+		return func(v []T) { fast_dst2_1d(v, n) }
+	}
+	return func(v []T) { dst2_1d(v, n) }
+}
+
+func dst3_kernel[T Float](n int) func([]T) {
+	if (n & (n - 1)) == 0 {
+		return func(v []T) { fast_dst3_1d(v, n) }
+	}
+	return func(v []T) { dst3_1d(v, n) }
+}
+
+func dst4_kernel[T Float](n int) func([]T) {
+	if (n & (n - 1)) == 0 {
+		return func(v []T) { fast_dst4_1d(v, n) }
+	}
+	return func(v []T) { dst4_1d(v, n) }
+}
+
+// naive reference implementations, straight from the definitions
+
+func dct1_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
+	factor := math.Pi / float64(size-1)
+
+	for i := 0; i < size; i++ {
+		sum := (inbuf[0] + inbuf[size-1]*T(1-2*(i&1))) / 2
+		for j := 1; j < size-1; j++ {
+			sum += inbuf[j] * T(math.Cos(float64(j*i)*factor))
+		}
+		temp[i] = sum
+	}
+
+	copy(inbuf, temp)
+}
+
+func dct4_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
+	factor := math.Pi / float64(size)
+
+	for i := 0; i < size; i++ {
+		var sum T
+		mult := (float64(i) + 0.5) * factor
+		for j := 0; j < size; j++ {
+			sum += inbuf[j] * T(math.Cos((float64(j)+0.5)*mult))
+		}
+		temp[i] = sum
+	}
+
+	copy(inbuf, temp)
+}
+
+func dst1_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
+	factor := math.Pi / float64(size+1)
+
+	for i := 0; i < size; i++ {
+		var sum T
+		mult := float64(i+1) * factor
+		for j := 0; j < size; j++ {
+			sum += inbuf[j] * T(math.Sin(float64(j+1)*mult))
+		}
+		temp[i] = sum
+	}
+
+	copy(inbuf, temp)
+}
+
+func dst2_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
+	factor := math.Pi / float64(size)
+
+	for i := 0; i < size; i++ {
+		var sum T
+		mult := float64(i+1) * factor
+		for j := 0; j < size; j++ {
+			sum += inbuf[j] * T(math.Sin((float64(j)+0.5)*mult))
+		}
+		temp[i] = sum
+	}
+
+	copy(inbuf, temp)
+}
+
+func dst3_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
+	factor := math.Pi / float64(size)
+
+	for i := 0; i < size; i++ {
+		sum := inbuf[size-1] * T(1-2*(i&1)) / 2
+		mult := (float64(i) + 0.5) * factor
+		for j := 0; j < size-1; j++ {
+			sum += inbuf[j] * T(math.Sin(float64(j+1)*mult))
+		}
+		temp[i] = sum
+	}
+
+	copy(inbuf, temp)
+}
+
+func dst4_1d[T Float](inbuf []T, size int) {
+	temp := make([]T, size)
+	factor := math.Pi / float64(size)
+
+	for i := 0; i < size; i++ {
+		var sum T
+		mult := (float64(i) + 0.5) * factor
+		for j := 0; j < size; j++ {
+			sum += inbuf[j] * T(math.Sin((float64(j)+0.5)*mult))
+		}
+		temp[i] = sum
+	}
+
+	copy(inbuf, temp)
+}
+
+// fast_dct3_1d is the unscaled DCT-III, the transpose of the DCT-II, for a
+// power of 2 size.
+func fast_dct3_1d[T Float](inbuf []T, size int) {
+	coef := make([]T, size)
+	temp := make([]T, size)
+
+	fast_dct_coef(size, coef)
+
+	inbuf[0] /= 2
+	inverse_recursive(inbuf, temp, size, coef)
+}
+
+// fast_dct1_1d splits a DCT-I of size 2^m+1 into a DCT-I of size 2^(m-1)+1
+// for the even outputs and a DCT-III of size 2^(m-1) for the odd ones.
+func fast_dct1_1d[T Float](inbuf []T, size int) {
+	m := size - 1
+	if m == 1 {
+		x, y := inbuf[0], inbuf[1]
+		inbuf[0], inbuf[1] = (x+y)/2, (x-y)/2
+		return
+	}
+
+	half := m / 2
+	even := make([]T, half+1)
+	odd := make([]T, half)
+	for i := 0; i < half; i++ {
+		even[i] = inbuf[i] + inbuf[m-i]
+		odd[i] = inbuf[i] - inbuf[m-i]
+	}
+	even[half] = 2 * inbuf[half]
+
+	fast_dct1_1d(even, half+1)
+	fast_dct3_1d(odd, half)
+
+	for i := 0; i < half; i++ {
+		inbuf[2*i] = even[i]
+		inbuf[2*i+1] = odd[i]
+	}
+	inbuf[m] = even[half]
+}
+
+// fast_dct4_1d uses 2 cos(a) cos(b) = cos(a+b) + cos(a-b): the DCT-II of
+// x[n] 2 cos(pi/N (n+0.5)/2) is Y[k] = X[k] + X[k-1], with Y[0] = 2 X[0].
+func fast_dct4_1d[T Float](inbuf []T, size int) {
+	factor := math.Pi / float64(2*size)
+	for i := 0; i < size; i++ {
+		inbuf[i] *= T(2 * math.Cos((float64(i)+0.5)*factor))
+	}
+
+	fast_dct_1d(inbuf, size)
+
+	inbuf[0] /= 2
+	for i := 1; i < size; i++ {
+		inbuf[i] -= inbuf[i-1]
+	}
+}
+
+// fast_dst1_1d splits a DST-I of size 2^m-1 into a DST-III of size 2^(m-1)
+// for the even outputs and a DST-I of size 2^(m-1)-1 for the odd ones.
+func fast_dst1_1d[T Float](inbuf []T, size int) {
+	if size == 1 {
+		return
+	}
+
+	half := (size + 1) / 2
+	even := make([]T, half)
+	odd := make([]T, half-1)
+	for i := 0; i < half-1; i++ {
+		even[i] = inbuf[i] + inbuf[size-1-i]
+		odd[i] = inbuf[i] - inbuf[size-1-i]
+	}
+	even[half-1] = 2 * inbuf[half-1]
+
+	fast_dst3_1d(even, half)
+	fast_dst1_1d(odd, half-1)
+
+	for i := 0; i < half-1; i++ {
+		inbuf[2*i] = even[i]
+		inbuf[2*i+1] = odd[i]
+	}
+	inbuf[size-1] = even[half-1]
+}
+
+// fast_dst2_1d uses DST-II(x)[k] = DCT-II((-1)^n x[n])[N-1-k].
+func fast_dst2_1d[T Float](inbuf []T, size int) {
+	for i := 1; i < size; i += 2 {
+		inbuf[i] = -inbuf[i]
+	}
+
+	fast_dct_1d(inbuf, size)
+
+	slices.Reverse(inbuf[:size])
+}
+
+// fast_dst3_1d uses DST-III(x)[k] = (-1)^k DCT-III(x[N-1-n])[k].
+func fast_dst3_1d[T Float](inbuf []T, size int) {
+	slices.Reverse(inbuf[:size])
+
+	fast_dct3_1d(inbuf, size)
+
+	for i := 1; i < size; i += 2 {
+		inbuf[i] = -inbuf[i]
+	}
+}
+
+// fast_dst4_1d uses DST-IV(x)[k] = (-1)^k DCT-IV(x[N-1-n])[k].
+func fast_dst4_1d[T Float](inbuf []T, size int) {
+	slices.Reverse(inbuf[:size])
+
+	fast_dct4_1d(inbuf, size)
+
+	for i := 1; i < size; i += 2 {
+		inbuf[i] = -inbuf[i]
+	}
+}
@@ -0,0 +1,172 @@
+package imagehash
+
+import (
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// The block mean hash of blockhash.io, as its JavaScript and Python
+// references: the image, at its own size, is divided into bits x bits
+// blocks, each summing r+g+b of its pixels, a fully transparent pixel
+// counting as white, 765. Each quarter of the blocks, in row order, is split
+// at its median, and blocks within 1 of a median brighter than half white
+// are set.
+//
+// The hash is taken from the 8 bit non-premultiplied RGBA of the decoded
+// image rather than the shared grayscale stage, as the reference sums the
+// channels. Decoders differ in the colour conversion of JPEG, so only
+// lossless images are certain to give the reference's bits.
+
+// ErrBlockHashBits is returned for a blockhash size other than 8 or 16.
+var ErrBlockHashBits = errors.New("imagehash: blockhash bits is not 8 or 16")
+
+// BlockHash returns the precise blockhash of img with bits x bits blocks,
+// 8 or 16 as in the reference, where pixels straddling blocks are shared
+// between them by area. Its String form is "bh:" and the reference's hex.
+func BlockHash(img image.Image, bits int) (*ExtImageHash, error) {
+	values, w, h, err := blockValues(img, bits)
+	if err != nil {
+		return nil, err
+	}
+	if w%bits == 0 && h%bits == 0 {
+		return blockHashEven(values, w, h, bits), nil
+	}
+
+	blockWidth := float64(w) / float64(bits)
+	blockHeight := float64(h) / float64(bits)
+	blocks := make([]float64, bits*bits)
+
+	for y := 0; y < h; y++ {
+		top, bottom, weightTop, weightBottom := blockSpan(y, h, bits, blockHeight)
+		for x := 0; x < w; x++ {
+			left, right, weightLeft, weightRight := blockSpan(x, w, bits, blockWidth)
+			v := values[y*w+x]
+			blocks[top*bits+left] += v * weightTop * weightLeft
+			blocks[top*bits+right] += v * weightTop * weightRight
+			blocks[bottom*bits+left] += v * weightBottom * weightLeft
+			blocks[bottom*bits+right] += v * weightBottom * weightRight
+		}
+	}
+	return NewExtImageHash(blockBits(blocks, blockWidth*blockHeight), BHash), nil
+}
+
+// BlockHashQuick returns the quick blockhash of img, on blocks of whole
+// pixels, dropping the pixels left over at the right and bottom. It equals
+// BlockHash when the sides divide by bits.
+func BlockHashQuick(img image.Image, bits int) (*ExtImageHash, error) {
+	values, w, h, err := blockValues(img, bits)
+	if err != nil {
+		return nil, err
+	}
+	return blockHashEven(values, w, h, bits), nil
+}
+
+// ParseBlockHash parses a blockhash in the reference's plain hex, 16 digits
+// for 8 bits or 64 for 16.
+func ParseBlockHash(s string) (*ExtImageHash, error) {
+	if len(s) != 16 && len(s) != 64 {
+		return nil, ErrInvalidHash
+	}
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidHash
+	}
+
+	hash := make([]uint64, len(buf)/8)
+	for i := range hash {
+		for j := 0; j < 8; j++ {
+			hash[i] = hash[i]<<8 | uint64(buf[8*i+j])
+		}
+	}
+	return NewExtImageHash(hash, BHash), nil
+}
+
+// blockValues returns r+g+b of each pixel of img in row order, 765 where it
+// is transparent.
+func blockValues(img image.Image, bits int) (values []float64, w, h int, err error) {
+	if img == nil {
+		return nil, 0, 0, ErrNilImage
+	}
+	if bits != 8 && bits != 16 {
+		return nil, 0, 0, ErrBlockHashBits
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, 0, 0, ErrEmptyImage
+	}
+
+	w, h = b.Dx(), b.Dy()
+	values = make([]float64, 0, w*h)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A == 0 {
+				values = append(values, 765)
+			} else {
+				values = append(values, float64(c.R)+float64(c.G)+float64(c.B))
+			}
+		}
+	}
+	return values, w, h, nil
+}
+
+// blockHashEven is the quick hash, on blocks of w/bits x h/bits pixels.
+func blockHashEven(values []float64, w, h, bits int) *ExtImageHash {
+	blockWidth, blockHeight := w/bits, h/bits
+	blocks := make([]float64, bits*bits)
+	for y := 0; y < bits*blockHeight; y++ {
+		for x := 0; x < bits*blockWidth; x++ {
+			blocks[(y/blockHeight)*bits+x/blockWidth] += values[y*w+x]
+		}
+	}
+	return NewExtImageHash(blockBits(blocks, float64(blockWidth*blockHeight)), BHash)
+}
+
+// blockSpan returns the blocks, first and second, that pixel i of a side of
+// n pixels falls into, and its weight in each, as the reference: a pixel
+// whose far edge is inside a block is shared with the block before by the
+// fraction past the boundary.
+func blockSpan(i, n, bits int, size float64) (first, second int, weightFirst, weightSecond float64) {
+	if n%bits == 0 {
+		b := int(math.Floor(float64(i) / size))
+		return b, b, 1, 0
+	}
+
+	mod := math.Mod(float64(i+1), size)
+	whole, frac := math.Modf(mod)
+	first = int(math.Floor(float64(i) / size))
+	second = first
+	if whole == 0 && i+1 != n {
+		second = int(math.Ceil(float64(i) / size))
+	}
+	return first, second, 1 - frac, frac
+}
+
+// blockBits sets each block above the median of its quarter, or within 1 of
+// a median above half the white sum of a block, most significant bit first.
+func blockBits(blocks []float64, pixelsPerBlock float64) []uint64 {
+	half := pixelsPerBlock * 256 * 3 / 2
+	band := len(blocks) / 4
+	hash := make([]uint64, (len(blocks)+63)/64)
+
+	for i := 0; i < 4; i++ {
+		sorted := slices.Clone(blocks[i*band : (i+1)*band])
+		slices.Sort(sorted)
+		m := sorted[band/2]
+		if band%2 == 0 {
+			m = (sorted[band/2-1] + sorted[band/2]) / 2
+		}
+
+		for j := i * band; j < (i+1)*band; j++ {
+			v := blocks[j]
+			if v > m || (math.Abs(v-m) < 1 && m > half) {
+				hash[j/64] |= 1 << (63 - j%64)
+			}
+		}
+	}
+	return hash
+}
@@ -0,0 +1,298 @@
+package imagehash
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/anthonynsimon/bild/transform"
+
+	"go.local/go-image-phash/dct"
+	"go.local/go-image-phash/transforms"
+)
+
+// ColorSpace selects the planes of ColorPerceptionHash.
+type ColorSpace int
+
+const (
+	// YCbCr is JPEG's full range luma and blue and red chroma.
+	YCbCr ColorSpace = iota
+	// HSV is hue, saturation and value.
+	HSV
+)
+
+func (c ColorSpace) String() string {
+	switch c {
+	case YCbCr:
+		return "YCbCr"
+	case HSV:
+		return "HSV"
+	}
+	return "Unknown"
+}
+
+const (
+	// colorMomentSize is the side of the image the moments are taken over.
+	colorMomentSize = 64
+
+	// colorFlat is the standard deviation, in levels of 255, below which a
+	// plane is taken as flat and hashes to 0, so the chroma of a gray image
+	// does not hash its rounding.
+	colorFlat = 0.5
+)
+
+// ColorPerceptionHash returns the pHash of each plane of img in space, the
+// 32x32 image's 8x8 lowest DCT-II coefficients split at their median, as
+// three words: Y, Cb and Cr, or H, S and V. A flat plane hashes to 0, and
+// otherwise the Y word is PerceptionHash. Hue wraps around at red, so the H
+// word of reddish images is less stable than the others.
+func ColorPerceptionHash(img image.Image, space ColorSpace) (*ExtImageHash, error) {
+	planes, err := colorPlanes(img, space, pHashSize)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := PHashYCbCr
+	if space == HSV {
+		kind = PHashHSV
+	}
+	hash := make([]uint64, len(planes))
+	for i, plane := range planes {
+		if stddev(plane) < colorFlat/255*planeScale(space) {
+			continue
+		}
+		coef, err := dct.PartialDCT2D(plane, pHashSize, pHashCorner)
+		if err != nil {
+			return nil, err
+		}
+		hash[i] = hashAbove(coef, medianOf(coef))
+	}
+	return NewExtImageHash(hash, kind), nil
+}
+
+// ColorMoments holds the mean, standard deviation and skewness, as the cube
+// root of the third central moment, of each YCbCr plane of an image scaled
+// to [0, 1], the colour moments of Stricker and Orengo.
+type ColorMoments struct {
+	moments [9]float64
+	kind    Kind
+}
+
+// NewColorMoments returns ColorMoments holding moments, the mean, standard
+// deviation and skewness of Y, then Cb, then Cr.
+func NewColorMoments(moments [9]float64) *ColorMoments {
+	return &ColorMoments{moments: moments, kind: ColorMoment}
+}
+
+// GetHash returns the moments of m.
+func (m *ColorMoments) GetHash() [9]float64 {
+	return m.moments
+}
+
+// GetKind returns the algorithm m was computed with.
+func (m *ColorMoments) GetKind() Kind {
+	return m.kind
+}
+
+// ColorMomentHash returns the colour moments of img, which tell its colours
+// apart but not how they are laid out.
+func ColorMomentHash(img image.Image) (*ColorMoments, error) {
+	planes, err := colorPlanes(img, YCbCr, colorMomentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var moments [9]float64
+	for i, plane := range planes {
+		var mean float64
+		for _, v := range plane {
+			mean += v / 255
+		}
+		mean /= float64(len(plane))
+
+		var m2, m3 float64
+		for _, v := range plane {
+			d := v/255 - mean
+			m2 += d * d
+			m3 += d * d * d
+		}
+		moments[3*i] = mean
+		moments[3*i+1] = math.Sqrt(m2 / float64(len(plane)))
+		moments[3*i+2] = math.Cbrt(m3 / float64(len(plane)))
+	}
+	return NewColorMoments(moments), nil
+}
+
+// Distance returns the sum of the absolute differences of the moments of m
+// and other, or ErrKindMismatch if they are of different kinds or either is
+// nil.
+func (m *ColorMoments) Distance(other *ColorMoments) (float64, error) {
+	if m == nil || other == nil || m.kind != other.kind {
+		return -1, ErrKindMismatch
+	}
+	var d float64
+	for i, v := range m.moments {
+		d += math.Abs(v - other.moments[i])
+	}
+	return d, nil
+}
+
+// String returns m as its kind tag and its moments, such as
+// "cm:0.5012,0.2033,...".
+func (m *ColorMoments) String() string {
+	prefix, ok := kindPrefix[m.kind]
+	if !ok {
+		prefix = "?"
+	}
+	values := make([]string, len(m.moments))
+	for i, v := range m.moments {
+		values[i] = fmt.Sprintf("%.4f", v)
+	}
+	return prefix + ":" + strings.Join(values, ",")
+}
+
+// ColorVerdict is the outcome of comparing two images by colour.
+type ColorVerdict int
+
+const (
+	// Different images show different things.
+	Different ColorVerdict = iota
+	// ColorVariant images show the same thing in other colours.
+	ColorVariant
+	// Duplicate images show the same thing in the same colours.
+	Duplicate
+)
+
+func (v ColorVerdict) String() string {
+	switch v {
+	case Different:
+		return "Different"
+	case ColorVariant:
+		return "ColorVariant"
+	case Duplicate:
+		return "Duplicate"
+	}
+	return "Unknown"
+}
+
+// ColorDistance is the combined distance between two images of CompareColor.
+type ColorDistance struct {
+	// Luma is the Hamming distance between the Y words.
+	Luma int
+	// Chroma is the Hamming distance between the Cb and Cr words together.
+	Chroma int
+	// Moments is the ColorMoments distance.
+	Moments float64
+}
+
+// CompareColor returns the distance between two images from their YCbCr
+// ColorPerceptionHash and ColorMomentHash, or ErrKindMismatch if any of them
+// is nil or of another kind.
+func CompareColor(a, b *ExtImageHash, ma, mb *ColorMoments) (ColorDistance, error) {
+	if a == nil || b == nil || a.kind != PHashYCbCr || b.kind != PHashYCbCr {
+		return ColorDistance{}, ErrKindMismatch
+	}
+	if len(a.hash) != 3 || len(b.hash) != 3 {
+		return ColorDistance{}, ErrLengthMismatch
+	}
+	moments, err := ma.Distance(mb)
+	if err != nil {
+		return ColorDistance{}, err
+	}
+
+	luma, err := NewImageHash(a.hash[0], PHash).Distance(NewImageHash(b.hash[0], PHash))
+	if err != nil {
+		return ColorDistance{}, err
+	}
+	chroma, err := NewExtImageHash(a.hash[1:], a.kind).Distance(NewExtImageHash(b.hash[1:], b.kind))
+	if err != nil {
+		return ColorDistance{}, err
+	}
+	return ColorDistance{Luma: luma, Chroma: chroma, Moments: moments}, nil
+}
+
+// ColorThresholds are the largest distances VerdictWith takes as the same
+// content or the same colours.
+type ColorThresholds struct {
+	// Luma is the largest Luma distance of the same content.
+	Luma int
+	// Chroma is the largest Chroma distance of the same colours.
+	Chroma int
+	// Moments is the largest Moments distance of the same colours.
+	Moments float64
+}
+
+// DefaultColorThresholds are the thresholds of Verdict. They sit midway
+// between the distances of the synthetic product shots of TestColorHashes,
+// recoloured, resized, shifted and recompressed, and have not been calibrated
+// on photographs; pass thresholds fit to the images at hand to VerdictWith.
+var DefaultColorThresholds = ColorThresholds{Luma: 14, Chroma: 16, Moments: 0.1}
+
+// Verdict is VerdictWith with DefaultColorThresholds.
+func (d ColorDistance) Verdict() ColorVerdict {
+	return d.VerdictWith(DefaultColorThresholds)
+}
+
+// VerdictWith tells whether the images of d show different things, the
+// same thing in other colours, or the same thing in the same colours, by
+// thresholds t. The luma hash decides what is shown, and the chroma hashes,
+// for where the colours are, and the moments, for which colours, decide
+// whether they changed.
+func (d ColorDistance) VerdictWith(t ColorThresholds) ColorVerdict {
+	switch {
+	case d.Luma > t.Luma:
+		return Different
+	case d.Chroma > t.Chroma || d.Moments > t.Moments:
+		return ColorVariant
+	}
+	return Duplicate
+}
+
+// colorPlanes returns the three planes of img in space, resized to size x
+// size.
+func colorPlanes(img image.Image, space ColorSpace, size int) ([3][]float64, error) {
+	var planes [3][]float64
+	if img == nil {
+		return planes, ErrNilImage
+	}
+	if b := img.Bounds(); b.Empty() {
+		return planes, ErrEmptyImage
+	} else if b.Dx() != size || b.Dy() != size {
+		img = transform.Resize(img, size, size, transform.Linear)
+	}
+
+	for i := range planes {
+		planes[i] = make([]float64, size*size)
+	}
+	if space == HSV {
+		transforms.Rgb2HSVRect(img, planes[0], planes[1], planes[2])
+	} else {
+		transforms.Rgb2YCbCrRect(img, planes[0], planes[1], planes[2])
+	}
+	return planes, nil
+}
+
+// planeScale is the full scale of the planes of space: 255 for YCbCr and 1
+// for HSV.
+func planeScale(space ColorSpace) float64 {
+	if space == HSV {
+		return 1
+	}
+	return 255
+}
+
+// stddev returns the standard deviation of values.
+func stddev(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var m2 float64
+	for _, v := range values {
+		m2 += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(m2 / float64(len(values)))
+}
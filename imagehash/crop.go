@@ -0,0 +1,266 @@
+package imagehash
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.local/go-image-phash/transforms"
+)
+
+// The crop resistant hash of Python imagehash's crop_resistant_hash with its
+// defaults: the grayscale, blurred and median filtered at 300x300, is split
+// into 4-connected regions of pixels above the threshold and of those at or
+// below it, and the pHash of the bounding box, in the original image, of
+// each region of more than 500 pixels is kept. A crop removes some regions
+// but leaves the others, and their hashes, unchanged.
+//
+// The reference stops looking for dark regions early, as it counts the
+// pixels around the border among those segmented; here every region is
+// found.
+const (
+	cropSize      = 300
+	cropSigma     = 2.0
+	cropMedian    = 3
+	cropThreshold = 128
+	cropMinPixels = 500
+
+	// cropBitErrorRate is the fraction of bits two region hashes may differ
+	// in to match.
+	cropBitErrorRate = 0.25
+)
+
+// MultiHash is the crop resistant hash of an image, the pHashes of its
+// regions, largest first where CropResistantHashLimit dropped some.
+type MultiHash struct {
+	hashes []*ImageHash
+	kind   Kind
+}
+
+// NewMultiHash returns a MultiHash of the region hashes.
+func NewMultiHash(hashes []*ImageHash) *MultiHash {
+	return &MultiHash{hashes: hashes, kind: CropResistant}
+}
+
+// GetHash returns the region hashes of h.
+func (h *MultiHash) GetHash() []*ImageHash {
+	return h.hashes
+}
+
+// GetKind returns the algorithm h was computed with.
+func (h *MultiHash) GetKind() Kind {
+	return h.kind
+}
+
+// CropResistantHash returns the crop resistant hash of img, which matches
+// an image to crops of it that keep at least one of its regions whole.
+func CropResistantHash(img image.Image) (*MultiHash, error) {
+	return CropResistantHashLimit(img, 0)
+}
+
+// CropResistantHashLimit is CropResistantHash keeping only the limit largest
+// regions, or all of them for limit 0.
+func CropResistantHashLimit(img image.Image, limit int) (*MultiHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	pixels := grayscale(img, cropSize, cropSize)
+	pixels = transforms.GaussianBlur(pixels, cropSize, cropSize, cropSigma)
+	pixels = transforms.Median(pixels, cropSize, cropSize, cropMedian)
+
+	regions := cropRegions(pixels, cropSize, cropThreshold, cropMinPixels)
+	if len(regions) == 0 {
+		regions = []cropRegion{{box: image.Rect(0, 0, cropSize, cropSize), n: cropSize * cropSize}}
+	}
+	if limit > 0 && len(regions) > limit {
+		slices.SortStableFunc(regions, func(a, b cropRegion) int { return b.n - a.n })
+		regions = regions[:limit]
+	}
+
+	scaleX := float64(b.Dx()) / cropSize
+	scaleY := float64(b.Dy()) / cropSize
+	hashes := make([]*ImageHash, len(regions))
+	for i, r := range regions {
+		box := image.Rect(
+			b.Min.X+int(math.RoundToEven(float64(r.box.Min.X)*scaleX)),
+			b.Min.Y+int(math.RoundToEven(float64(r.box.Min.Y)*scaleY)),
+			b.Min.X+int(math.RoundToEven(float64(r.box.Max.X)*scaleX)),
+			b.Min.Y+int(math.RoundToEven(float64(r.box.Max.Y)*scaleY)),
+		).Intersect(b)
+		if box.Empty() {
+			box = b
+		}
+
+		h, err := PerceptionHash(crop(img, box))
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h
+	}
+	return NewMultiHash(hashes), nil
+}
+
+// RegionMatches returns how many regions of h have a region of other
+// within cutoff bits, and the sum of their distances, as the reference's
+// hash_diff. A nil hash gives ErrKindMismatch.
+func (h *MultiHash) RegionMatches(other *MultiHash, cutoff int) (matches, sum int, err error) {
+	if h == nil || other == nil || h.kind != other.kind {
+		return 0, 0, ErrKindMismatch
+	}
+	if len(other.hashes) == 0 {
+		return 0, 0, nil
+	}
+
+	for _, a := range h.hashes {
+		best := -1
+		for _, b := range other.hashes {
+			d, err := a.Distance(b)
+			if err != nil {
+				return 0, 0, err
+			}
+			if best < 0 || d < best {
+				best = d
+			}
+		}
+		if best <= cutoff {
+			matches++
+			sum += best
+		}
+	}
+	return matches, sum, nil
+}
+
+// Matches reports whether a region of h matches one of other, within a
+// quarter of the bits.
+func (h *MultiHash) Matches(other *MultiHash) (bool, error) {
+	matches, _, err := h.RegionMatches(other, cropCutoff())
+	return matches > 0, err
+}
+
+// Distance returns the number of regions of h less those matching other,
+// with the mean fraction of bits the matches differ in breaking ties, as
+// the reference's difference of ImageMultiHash: 0 for equal hashes and the
+// number of regions of h for no match. It is not symmetric.
+func (h *MultiHash) Distance(other *MultiHash) (float64, error) {
+	matches, sum, err := h.RegionMatches(other, cropCutoff())
+	if err != nil {
+		return -1, err
+	}
+	regions := float64(len(h.hashes))
+	if matches == 0 {
+		return regions, nil
+	}
+	return regions - float64(matches) + float64(sum)/float64(matches*64), nil
+}
+
+// String returns h as its kind tag and the hex digits of each region hash,
+// separated by commas, such as "cr:8f373714acfcf4d0,07f3f3e3c3c7cf8f".
+func (h *MultiHash) String() string {
+	prefix, ok := kindPrefix[h.kind]
+	if !ok {
+		prefix = "?"
+	}
+	regions := make([]string, len(h.hashes))
+	for i, r := range h.hashes {
+		regions[i] = fmt.Sprintf("%016x", r.GetHash())
+	}
+	return prefix + ":" + strings.Join(regions, ",")
+}
+
+// ParseMultiHash parses the String form of a MultiHash.
+func ParseMultiHash(s string) (*MultiHash, error) {
+	list, ok := strings.CutPrefix(s, kindPrefix[CropResistant]+":")
+	if !ok || list == "" {
+		return nil, ErrInvalidHash
+	}
+
+	var hashes []*ImageHash
+	for _, digits := range strings.Split(list, ",") {
+		if len(digits) != 16 {
+			return nil, ErrInvalidHash
+		}
+		hash, err := strconv.ParseUint(digits, 16, 64)
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		hashes = append(hashes, NewImageHash(hash, PHash))
+	}
+	return NewMultiHash(hashes), nil
+}
+
+// cropCutoff is the most bits two region hashes may differ in to match.
+func cropCutoff() int {
+	return int(64 * cropBitErrorRate)
+}
+
+// cropRegion is the bounding box of a region and its number of pixels.
+type cropRegion struct {
+	box image.Rectangle
+	n   int
+}
+
+// cropRegions returns the 4-connected regions of the size x size pixels
+// above threshold, then those at or below it, each in the order of their
+// first pixel, that have more than minPixels pixels.
+func cropRegions(pixels []float64, size int, threshold float64, minPixels int) []cropRegion {
+	seen := make([]bool, len(pixels))
+	var regions []cropRegion
+	var stack []int
+
+	for _, above := range []bool{true, false} {
+		for start := range pixels {
+			if seen[start] || (pixels[start] > threshold) != above {
+				continue
+			}
+
+			r := cropRegion{box: image.Rect(start%size, start/size, start%size+1, start/size+1)}
+			seen[start] = true
+			stack = append(stack[:0], start)
+			for len(stack) > 0 {
+				i := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				x, y := i%size, i/size
+				r.n++
+				r.box = r.box.Union(image.Rect(x, y, x+1, y+1))
+
+				for _, j := range [4]int{i - size, i + size, i - 1, i + 1} {
+					switch {
+					case j < 0 || j >= len(pixels):
+						continue
+					case (j == i-1 && x == 0) || (j == i+1 && x == size-1):
+						continue
+					case seen[j] || (pixels[j] > threshold) != above:
+						continue
+					}
+					seen[j] = true
+					stack = append(stack, j)
+				}
+			}
+			if r.n > minPixels {
+				regions = append(regions, r)
+			}
+		}
+	}
+	return regions
+}
+
+// crop returns the part of img in r.
+func crop(img image.Image, r image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	out := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(out, out.Bounds(), img, r.Min, draw.Src)
+	return out
+}
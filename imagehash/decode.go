@@ -0,0 +1,80 @@
+package imagehash
+
+import (
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"regexp"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/webp"
+)
+
+// ErrUnsupportedType is returned decoding content of a type other than bmp,
+// gif, jpeg, png or webp.
+var ErrUnsupportedType = errors.New("imagehash: unsupported content type")
+
+var (
+	reImageBmp  = regexp.MustCompile(`(?i:bmp)$`)
+	reImageGif  = regexp.MustCompile(`(?i:gif)$`)
+	reImageJpg  = regexp.MustCompile(`(?i:jpe{0,1}g)$`)
+	reImagePng  = regexp.MustCompile(`(?i:png)$`)
+	reImageWebp = regexp.MustCompile(`(?i:webp)$`)
+)
+
+// ContentTypeByExt returns the content type implied by the extension of path,
+// or "" if it is not one Decode supports.
+func ContentTypeByExt(path string) string {
+	switch {
+	case reImageBmp.MatchString(path):
+		return "image/bmp"
+	case reImageGif.MatchString(path):
+		return "image/gif"
+	case reImageJpg.MatchString(path):
+		return "image/jpeg"
+	case reImagePng.MatchString(path):
+		return "image/png"
+	case reImageWebp.MatchString(path):
+		return "image/webp"
+	}
+	return ""
+}
+
+// Decode sniffs the content type of r from its first 512 bytes and decodes
+// it, returning the image and the content type. Callers can compare the
+// type with ContentTypeByExt to catch misnamed files.
+func Decode(r io.ReadSeeker) (image.Image, string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, "", err
+	}
+	contentType := http.DetectContentType(buf[:n])
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, contentType, err
+	}
+
+	var img image.Image
+	switch contentType {
+	case "image/bmp":
+		img, err = bmp.Decode(r)
+	case "image/gif":
+		img, err = gif.Decode(r)
+	case "image/jpeg":
+		img, err = jpeg.Decode(r)
+	case "image/png":
+		img, err = png.Decode(r)
+	case "image/webp":
+		img, err = webp.Decode(r)
+	default:
+		return nil, contentType, ErrUnsupportedType
+	}
+	if err != nil {
+		return nil, contentType, err
+	}
+	return img, contentType, nil
+}
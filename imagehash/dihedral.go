@@ -0,0 +1,110 @@
+package imagehash
+
+// Dihedral is one of the 8 symmetries of a square image, the rotations and
+// reflections an uploader may apply to evade a hash. The names are PDQ's.
+type Dihedral int
+
+const (
+	// Original is the image as given.
+	Original Dihedral = iota
+	// Rotate90 is the image rotated 90 degrees counter-clockwise.
+	Rotate90
+	// Rotate180 is the image rotated 180 degrees.
+	Rotate180
+	// Rotate270 is the image rotated 90 degrees clockwise.
+	Rotate270
+	// FlipX is the image mirrored about the horizontal axis, upside down.
+	FlipX
+	// FlipY is the image mirrored about the vertical axis, left to right.
+	FlipY
+	// FlipPlus1 is the image mirrored about its main diagonal, transposed.
+	FlipPlus1
+	// FlipMinus1 is the image mirrored about its anti-diagonal.
+	FlipMinus1
+)
+
+// Dihedrals lists the symmetries in the order of the dihedral hashes.
+var Dihedrals = [8]Dihedral{Original, Rotate90, Rotate180, Rotate270, FlipX, FlipY, FlipPlus1, FlipMinus1}
+
+func (d Dihedral) String() string {
+	switch d {
+	case Original:
+		return "Original"
+	case Rotate90:
+		return "Rotate90"
+	case Rotate180:
+		return "Rotate180"
+	case Rotate270:
+		return "Rotate270"
+	case FlipX:
+		return "FlipX"
+	case FlipY:
+		return "FlipY"
+	case FlipPlus1:
+		return "FlipPlus1"
+	case FlipMinus1:
+		return "FlipMinus1"
+	}
+	return "Unknown"
+}
+
+// dihedralDCT returns the n x n block of 2D DCT-II coefficients coef, of
+// frequencies first to first+n-1 along each axis with the vertical one in
+// rows, for the image transformed by d. Mirroring an axis negates its odd
+// frequencies, and mirroring a diagonal transposes the block.
+func dihedralDCT(coef []float64, n, first int, d Dihedral) []float64 {
+	neg := func(f int) float64 {
+		if f&1 == 1 {
+			return -1
+		}
+		return 1
+	}
+
+	out := make([]float64, n*n)
+	for u := 0; u < n; u++ {
+		for v := 0; v < n; v++ {
+			c, t := coef[u*n+v], coef[v*n+u]
+			fu, fv := first+u, first+v
+			var x float64
+			switch d {
+			case Original:
+				x = c
+			case Rotate90:
+				x = t * neg(fu)
+			case Rotate180:
+				x = c * neg(fu+fv)
+			case Rotate270:
+				x = t * neg(fv)
+			case FlipX:
+				x = c * neg(fu)
+			case FlipY:
+				x = c * neg(fv)
+			case FlipPlus1:
+				x = t
+			case FlipMinus1:
+				x = t * neg(fu+fv)
+			}
+			out[u*n+v] = x
+		}
+	}
+	return out
+}
+
+// MinDihedralDistance returns the smallest Distance between h and the
+// hashes of the 8 Dihedrals of another image, as from AllDihedralHashes,
+// and the Dihedral that transforms the other image to match h, the first in
+// the order of Dihedrals on a tie. It returns ErrKindMismatch if h or a
+// variant is nil or of another kind.
+func MinDihedralDistance(h *ImageHash, variants [8]*ImageHash) (int, Dihedral, error) {
+	best, match := -1, Original
+	for i, v := range variants {
+		d, err := h.Distance(v)
+		if err != nil {
+			return -1, Original, err
+		}
+		if best < 0 || d < best {
+			best, match = d, Dihedrals[i]
+		}
+	}
+	return best, match, nil
+}
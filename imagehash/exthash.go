@@ -0,0 +1,106 @@
+package imagehash
+
+import (
+	"encoding/hex"
+	"math/bits"
+	"strings"
+)
+
+// ExtImageHash is a perceptual hash of any multiple of 64 bits, tagged with
+// its Kind. The words are big-endian, hash[0] holding the first 64 bits of
+// the String form.
+type ExtImageHash struct {
+	hash []uint64
+	kind Kind
+}
+
+// NewExtImageHash returns an ExtImageHash of kind holding hash.
+func NewExtImageHash(hash []uint64, kind Kind) *ExtImageHash {
+	return &ExtImageHash{hash: hash, kind: kind}
+}
+
+// GetHash returns the words of h.
+func (h *ExtImageHash) GetHash() []uint64 {
+	return h.hash
+}
+
+// GetKind returns the algorithm h was computed with.
+func (h *ExtImageHash) GetKind() Kind {
+	return h.kind
+}
+
+// Bits returns the length of h in bits.
+func (h *ExtImageHash) Bits() int {
+	return len(h.hash) * 64
+}
+
+// Distance returns the Hamming distance between h and other, or
+// ErrKindMismatch or ErrLengthMismatch if they cannot be compared, the former
+// if either is nil.
+func (h *ExtImageHash) Distance(other *ExtImageHash) (int, error) {
+	if h == nil || other == nil || h.kind != other.kind {
+		return -1, ErrKindMismatch
+	}
+	if len(h.hash) != len(other.hash) {
+		return -1, ErrLengthMismatch
+	}
+
+	d := 0
+	for i, w := range h.hash {
+		d += bits.OnesCount64(w ^ other.hash[i])
+	}
+	return d, nil
+}
+
+// NormalizedDistance returns the Distance between h and other as a fraction
+// of their length, as pHash's ph_hammingdistance2.
+func (h *ExtImageHash) NormalizedDistance(other *ExtImageHash) (float64, error) {
+	d, err := h.Distance(other)
+	if err != nil {
+		return -1, err
+	}
+	return float64(d) / float64(h.Bits()), nil
+}
+
+// String returns h as its kind tag and its words in hex, such as
+// "pdq:" and 64 digits for a PDQ hash.
+func (h *ExtImageHash) String() string {
+	prefix, ok := kindPrefix[h.kind]
+	if !ok {
+		prefix = "?"
+	}
+
+	buf := make([]byte, 8*len(h.hash))
+	for i, w := range h.hash {
+		for j := 0; j < 8; j++ {
+			buf[8*i+j] = byte(w >> (56 - 8*j))
+		}
+	}
+	return prefix + ":" + hex.EncodeToString(buf)
+}
+
+// ParseExtImageHash parses the String form of an ExtImageHash.
+func ParseExtImageHash(s string) (*ExtImageHash, error) {
+	prefix, digits, ok := strings.Cut(s, ":")
+	if !ok || len(digits) == 0 || len(digits)%16 != 0 {
+		return nil, ErrInvalidHash
+	}
+
+	for kind, p := range kindPrefix {
+		if p != prefix {
+			continue
+		}
+		buf, err := hex.DecodeString(digits)
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		hash := make([]uint64, len(buf)/8)
+		for i := range hash {
+			for j := 0; j < 8; j++ {
+				hash[i] = hash[i]<<8 | uint64(buf[8*i+j])
+			}
+		}
+		return NewExtImageHash(hash, kind), nil
+	}
+	return nil, ErrInvalidHash
+}
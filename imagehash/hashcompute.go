@@ -0,0 +1,194 @@
+package imagehash
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/anthonynsimon/bild/transform"
+
+	"go.local/go-image-phash/dct"
+	"go.local/go-image-phash/transforms"
+)
+
+const (
+	// pHashSize is the side of the image pHash transforms, as Image::PHash.
+	pHashSize = 32
+	// pHashCorner is the side of the low frequency block pHash keeps.
+	pHashCorner = 8
+)
+
+// grayscale is the stage all hashes share: img resized to w x h, if it is not
+// already, and converted to luminosity, row by row.
+func grayscale(img image.Image, w, h int) []float64 {
+	if b := img.Bounds(); b.Dx() != w || b.Dy() != h {
+		img = transform.Resize(img, w, h, transform.Linear)
+	}
+	pixels := make([]float64, w*h)
+	transforms.Rgb2GrayFastRect(img, &pixels)
+	return pixels
+}
+
+// PerceptionHash returns the pHash of img: the signs, against their median,
+// of the 8x8 lowest frequency 2D DCT-II coefficients of the 32x32 grayscale.
+func PerceptionHash(img image.Image) (*ImageHash, error) {
+	coef, err := pHashDCT(img)
+	if err != nil {
+		return nil, err
+	}
+
+	median := medianOf(coef)
+	return NewImageHash(hashAbove(coef, median), PHash), nil
+}
+
+// PerceptionHashInt is PerceptionHash with the grayscale rounded to 8 bits
+// and transformed by dct.DCT2DUint8, without floating point. Its bits are
+// those of the float DCT of the rounded grayscale but for coefficients within
+// dct.IntDCTErrorBound of the median. The rounding itself moves every
+// coefficient, so bits near the median may differ from PerceptionHash's:
+// textured images hash alike, while smooth ones, with many coefficients near
+// 0, can differ in many bits.
+func PerceptionHashInt(img image.Image) (*ImageHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	pixels := grayscale(img, pHashSize, pHashSize)
+	samples := make([]uint8, len(pixels))
+	for i, v := range pixels {
+		samples[i] = uint8(min(max(math.Round(v), 0), 255))
+	}
+	out, err := dct.DCT2DUint8(samples, pHashSize)
+	if err != nil {
+		return nil, err
+	}
+
+	coef := make([]float64, 0, pHashCorner*pHashCorner)
+	for y := 0; y < pHashCorner; y++ {
+		for _, c := range out[y*pHashSize : y*pHashSize+pHashCorner] {
+			coef = append(coef, float64(c))
+		}
+	}
+	return NewImageHash(hashAbove(coef, medianOf(coef)), PHash), nil
+}
+
+// AllDihedralHashes returns the pHashes of the 8 Dihedrals of img, in their
+// order, derived from one DCT by sign changes and transposes rather than by
+// transforming the image. Compare them with MinDihedralDistance.
+func AllDihedralHashes(img image.Image) ([8]*ImageHash, error) {
+	var hashes [8]*ImageHash
+	coef, err := pHashDCT(img)
+	if err != nil {
+		return hashes, err
+	}
+	for i, d := range Dihedrals {
+		out := dihedralDCT(coef, pHashCorner, 0, d)
+		hashes[i] = NewImageHash(hashAbove(out, medianOf(out)), PHash)
+	}
+	return hashes, nil
+}
+
+// pHashDCT returns the 8x8 lowest frequency 2D DCT-II coefficients of the
+// 32x32 grayscale of img.
+func pHashDCT(img image.Image) ([]float64, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	pixels := grayscale(img, pHashSize, pHashSize)
+	return dct.PartialDCT2D(pixels, pHashSize, pHashCorner)
+}
+
+// AverageHash returns the aHash of img: each pixel of the 8x8 grayscale
+// against their mean.
+func AverageHash(img image.Image) (*ImageHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	pixels := grayscale(img, 8, 8)
+	var sum float64
+	for _, p := range pixels {
+		sum += p
+	}
+	return NewImageHash(hashAbove(pixels, sum/float64(len(pixels))), AHash), nil
+}
+
+// DifferenceHash returns the horizontal dHash of img: whether each pixel of
+// the 9x8 grayscale is darker than its right neighbour.
+func DifferenceHash(img image.Image) (*ImageHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	pixels := grayscale(img, 9, 8)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if pixels[y*9+x] < pixels[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return NewImageHash(hash, DHash), nil
+}
+
+// DifferenceHashVertical returns the vertical dHash of img: whether each pixel
+// of the 8x9 grayscale is darker than the one below it.
+func DifferenceHashVertical(img image.Image) (*ImageHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	pixels := grayscale(img, 8, 9)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if pixels[y*8+x] < pixels[(y+1)*8+x] {
+				hash |= 1
+			}
+		}
+	}
+	return NewImageHash(hash, DHashVertical), nil
+}
+
+// hashAbove packs whether each of the 64 values exceeds threshold, the first
+// in the top bit.
+func hashAbove(values []float64, threshold float64) uint64 {
+	var hash uint64
+	for _, v := range values {
+		hash <<= 1
+		if v > threshold {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// medianOf returns the median of values, which it leaves untouched.
+func medianOf(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,430 @@
+package imagehash
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Digest is a hash computed by a Hasher: *ImageHash, *ExtImageHash,
+// *RadialDigest, *ColorMoments or *MultiHash.
+type Digest interface {
+	GetKind() Kind
+	String() string
+}
+
+// Params are the parameters of a Hasher by name, such as "bits" for
+// blockhash. Values are strings, as given on a command line.
+type Params map[string]string
+
+// String returns p sorted by name, as "bits=16,quick=false".
+func (p Params) String() string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + p[k]
+	}
+	return strings.Join(keys, ",")
+}
+
+// Hasher computes and compares one algorithm's hashes, so callers can use
+// any of them alike. Create one with NewHasher or ParseHasher.
+type Hasher interface {
+	// Name returns the registry name of the algorithm, such as "phash".
+	Name() string
+	// Params returns the parameters of the Hasher, defaults included.
+	Params() Params
+	// Hash returns the hash of img.
+	Hash(img image.Image) (Digest, error)
+	// Distance returns how far apart a and b are, 0 for equal: the
+	// Hamming distance in bits for bit hashes, 1 minus the peak
+	// cross-correlation for the radial digest, the moment distance for
+	// colour moments, and the unmatched regions for the crop resistant
+	// hash. It returns ErrKindMismatch for digests of another Hasher.
+	Distance(a, b Digest) (float64, error)
+}
+
+// HasherFactory returns a Hasher for params, filling in defaults for those
+// left out.
+type HasherFactory func(params Params) (Hasher, error)
+
+var (
+	// ErrUnknownHasher is returned for a name no Hasher is registered as.
+	ErrUnknownHasher = errors.New("imagehash: unknown hasher")
+	// ErrInvalidParam is returned for an unknown or malformed parameter.
+	ErrInvalidParam = errors.New("imagehash: invalid hasher parameter")
+)
+
+// ParamError reports a parameter a Hasher does not take or cannot parse. It
+// matches ErrInvalidParam with errors.Is.
+type ParamError struct {
+	Hasher string
+	Param  string
+	Value  string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("imagehash: %s: invalid parameter %s=%q", e.Hasher, e.Param, e.Value)
+}
+
+func (e *ParamError) Unwrap() error {
+	return ErrInvalidParam
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]HasherFactory{}
+)
+
+// Register makes a Hasher available by name. It panics if name is already
+// registered.
+func Register(name string, factory HasherFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("imagehash: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Hashers returns the registered names, sorted. They are names only: a
+// Hasher is identified by its name and parameters, as HasherSpec gives them.
+func Hashers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewHasher returns the Hasher registered as name with params.
+func NewHasher(name string, params Params) (Hasher, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownHasher, name)
+	}
+	return factory(params)
+}
+
+// ParseHasher returns the Hasher of spec, a name optionally followed by
+// parameters, such as "blockhash:bits=8,quick=true". HasherSpec is its
+// inverse.
+func ParseHasher(spec string) (Hasher, error) {
+	name, list, _ := strings.Cut(spec, ":")
+	params := Params{}
+	if list != "" {
+		for _, kv := range strings.Split(list, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				return nil, &ParamError{Hasher: name, Param: kv}
+			}
+			params[k] = v
+		}
+	}
+	return NewHasher(name, params)
+}
+
+// HasherSpec returns the name and parameters of h, the key it is registered
+// under with its configuration, such as "blockhash:bits=16,quick=false".
+func HasherSpec(h Hasher) string {
+	if params := h.Params(); len(params) > 0 {
+		return h.Name() + ":" + params.String()
+	}
+	return h.Name()
+}
+
+// hasher is a Hasher of a hash function and the distance of its digests.
+type hasher struct {
+	name     string
+	params   Params
+	kind     Kind
+	hash     func(image.Image) (Digest, error)
+	distance func(a, b Digest) (float64, error)
+}
+
+func (h *hasher) Name() string {
+	return h.name
+}
+
+func (h *hasher) Params() Params {
+	params := make(Params, len(h.params))
+	for k, v := range h.params {
+		params[k] = v
+	}
+	return params
+}
+
+func (h *hasher) Hash(img image.Image) (Digest, error) {
+	return h.hash(img)
+}
+
+func (h *hasher) Distance(a, b Digest) (float64, error) {
+	if a == nil || b == nil || a.GetKind() != h.kind || b.GetKind() != h.kind {
+		return -1, ErrKindMismatch
+	}
+	return h.distance(a, b)
+}
+
+// digest returns d as a Digest, or nil on err rather than a typed nil.
+func digest[D Digest](d D, err error) (Digest, error) {
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// hammingDistance is the Distance of *ImageHash and *ExtImageHash digests.
+func hammingDistance(a, b Digest) (float64, error) {
+	switch a := a.(type) {
+	case *ImageHash:
+		if b, ok := b.(*ImageHash); ok {
+			d, err := a.Distance(b)
+			return float64(d), err
+		}
+	case *ExtImageHash:
+		if b, ok := b.(*ExtImageHash); ok {
+			d, err := a.Distance(b)
+			return float64(d), err
+		}
+	}
+	return -1, ErrKindMismatch
+}
+
+// simpleHasher returns the factory of a Hasher without parameters.
+func simpleHasher(name string, kind Kind, hash func(image.Image) (Digest, error), distance func(a, b Digest) (float64, error)) HasherFactory {
+	return func(params Params) (Hasher, error) {
+		if err := unknownParam(name, params); err != nil {
+			return nil, err
+		}
+		return &hasher{name: name, params: Params{}, kind: kind, hash: hash, distance: distance}, nil
+	}
+}
+
+// choice returns the value of param in params, def if it is left out, or a
+// *ParamError if it is not one of values.
+func choice(name string, params Params, param, def string, values ...string) (string, error) {
+	v, ok := params[param]
+	if !ok {
+		return def, nil
+	}
+	if !slices.Contains(values, v) {
+		return "", &ParamError{Hasher: name, Param: param, Value: v}
+	}
+	return v, nil
+}
+
+// unknownParam returns a *ParamError for the first of params not in known.
+func unknownParam(name string, params Params, known ...string) error {
+	for k, v := range params {
+		if !slices.Contains(known, k) {
+			return &ParamError{Hasher: name, Param: k, Value: v}
+		}
+	}
+	return nil
+}
+
+func init() {
+	for _, h := range []struct {
+		name string
+		kind Kind
+		hash func(image.Image) (*ImageHash, error)
+	}{
+		{"ahash", AHash, AverageHash},
+		{"dhash", DHash, DifferenceHash},
+		{"dhashv", DHashVertical, DifferenceHashVertical},
+	} {
+		hash := h.hash
+		Register(h.name, simpleHasher(h.name, h.kind, func(img image.Image) (Digest, error) {
+			return digest(hash(img))
+		}, hammingDistance))
+	}
+
+	// dct=int takes PerceptionHashInt, the pHash by the integer DCT.
+	Register("phash", func(params Params) (Hasher, error) {
+		if err := unknownParam("phash", params, "dct"); err != nil {
+			return nil, err
+		}
+		d, err := choice("phash", params, "dct", "float", "float", "int")
+		if err != nil {
+			return nil, err
+		}
+		hash := PerceptionHash
+		if d == "int" {
+			hash = PerceptionHashInt
+		}
+		return &hasher{
+			name:     "phash",
+			params:   Params{"dct": d},
+			kind:     PHash,
+			hash:     func(img image.Image) (Digest, error) { return digest(hash(img)) },
+			distance: hammingDistance,
+		}, nil
+	})
+
+	// minquality, 0 to 100, makes Hash fail with ErrLowQuality below it; the
+	// reference advises against trusting hashes below about 50.
+	Register("pdq", func(params Params) (Hasher, error) {
+		if err := unknownParam("pdq", params, "minquality"); err != nil {
+			return nil, err
+		}
+		minQuality := 0
+		if v, ok := params["minquality"]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 100 {
+				return nil, &ParamError{Hasher: "pdq", Param: "minquality", Value: v}
+			}
+			minQuality = n
+		}
+
+		return &hasher{
+			name:   "pdq",
+			params: Params{"minquality": strconv.Itoa(minQuality)},
+			kind:   PDQ,
+			hash: func(img image.Image) (Digest, error) {
+				h, quality, err := PDQHash(img)
+				if err == nil && quality < minQuality {
+					return nil, fmt.Errorf("%w: %d", ErrLowQuality, quality)
+				}
+				return digest(h, err)
+			},
+			distance: hammingDistance,
+		}, nil
+	})
+
+	Register("mhash", simpleHasher("mhash", MHash, func(img image.Image) (Digest, error) {
+		return digest(MarrHildrethHash(img))
+	}, hammingDistance))
+
+	Register("radial", simpleHasher("radial", RadialVariance, func(img image.Image) (Digest, error) {
+		return digest(RadialVarianceHash(img))
+	}, func(a, b Digest) (float64, error) {
+		ra, ok := a.(*RadialDigest)
+		rb, okb := b.(*RadialDigest)
+		if !ok || !okb {
+			return -1, ErrKindMismatch
+		}
+		peak, err := ra.CrossCorrelation(rb)
+		if err != nil {
+			return -1, err
+		}
+		return 1 - peak, nil
+	}))
+
+	Register("colormoments", simpleHasher("colormoments", ColorMoment, func(img image.Image) (Digest, error) {
+		return digest(ColorMomentHash(img))
+	}, func(a, b Digest) (float64, error) {
+		ma, ok := a.(*ColorMoments)
+		mb, okb := b.(*ColorMoments)
+		if !ok || !okb {
+			return -1, ErrKindMismatch
+		}
+		return ma.Distance(mb)
+	}))
+
+	Register("cropresistant", func(params Params) (Hasher, error) {
+		if err := unknownParam("cropresistant", params, "limit"); err != nil {
+			return nil, err
+		}
+		limit := 0
+		if v, ok := params["limit"]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, &ParamError{Hasher: "cropresistant", Param: "limit", Value: v}
+			}
+			limit = n
+		}
+
+		return &hasher{
+			name:   "cropresistant",
+			params: Params{"limit": strconv.Itoa(limit)},
+			kind:   CropResistant,
+			hash:   func(img image.Image) (Digest, error) { return digest(CropResistantHashLimit(img, limit)) },
+			distance: func(a, b Digest) (float64, error) {
+				ma, ok := a.(*MultiHash)
+				mb, okb := b.(*MultiHash)
+				if !ok || !okb {
+					return -1, ErrKindMismatch
+				}
+				return ma.Distance(mb)
+			},
+		}, nil
+	})
+
+	Register("whash", func(params Params) (Hasher, error) {
+		if err := unknownParam("whash", params, "wavelet"); err != nil {
+			return nil, err
+		}
+		w, err := choice("whash", params, "wavelet", "haar", "haar", "d4")
+		if err != nil {
+			return nil, err
+		}
+		h := &hasher{name: "whash", params: Params{"wavelet": w}, kind: WHash, distance: hammingDistance}
+		h.hash = func(img image.Image) (Digest, error) { return digest(WaveletHash(img)) }
+		if w == "d4" {
+			h.kind = WHashD4
+			h.hash = func(img image.Image) (Digest, error) { return digest(WaveletHashD4(img)) }
+		}
+		return h, nil
+	})
+
+	Register("blockhash", func(params Params) (Hasher, error) {
+		if err := unknownParam("blockhash", params, "bits", "quick"); err != nil {
+			return nil, err
+		}
+		b, err := choice("blockhash", params, "bits", "16", "8", "16")
+		if err != nil {
+			return nil, err
+		}
+		bits, _ := strconv.Atoi(b)
+		quick, err := choice("blockhash", params, "quick", "false", "false", "true")
+		if err != nil {
+			return nil, err
+		}
+
+		hash := BlockHash
+		if quick == "true" {
+			hash = BlockHashQuick
+		}
+		return &hasher{
+			name:     "blockhash",
+			params:   Params{"bits": b, "quick": quick},
+			kind:     BHash,
+			hash:     func(img image.Image) (Digest, error) { return digest(hash(img, bits)) },
+			distance: hammingDistance,
+		}, nil
+	})
+
+	Register("colorphash", func(params Params) (Hasher, error) {
+		if err := unknownParam("colorphash", params, "space"); err != nil {
+			return nil, err
+		}
+		s, err := choice("colorphash", params, "space", "ycbcr", "ycbcr", "hsv")
+		if err != nil {
+			return nil, err
+		}
+
+		space, kind := YCbCr, PHashYCbCr
+		if s == "hsv" {
+			space, kind = HSV, PHashHSV
+		}
+		return &hasher{
+			name:     "colorphash",
+			params:   Params{"space": s},
+			kind:     kind,
+			hash:     func(img image.Image) (Digest, error) { return digest(ColorPerceptionHash(img, space)) },
+			distance: hammingDistance,
+		}, nil
+	})
+}
@@ -0,0 +1,178 @@
+// Package imagehash computes perceptual hashes of images: the DCT based
+// pHash of Image::PHash, the wavelet hash, and the cheaper average and
+// difference hashes for a first pass. Each hash carries its Kind, so values
+// of different algorithms are never compared with each other.
+//
+// Every algorithm is also registered as a Hasher, which ParseHasher selects
+// by name and parameters, such as "phash" or "blockhash:bits=8".
+package imagehash
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Kind identifies the algorithm an ImageHash was computed with.
+type Kind int
+
+const (
+	// Unknown is the Kind of the zero ImageHash.
+	Unknown Kind = iota
+	// PHash is the DCT based perceptual hash.
+	PHash
+	// AHash is the average hash.
+	AHash
+	// DHash is the horizontal difference hash.
+	DHash
+	// DHashVertical is the vertical difference hash.
+	DHashVertical
+	// WHash is the Haar wavelet hash.
+	WHash
+	// WHashD4 is the wavelet hash on the Daubechies-4 wavelet.
+	WHashD4
+	// PDQ is Facebook's 256 bit PDQ hash.
+	PDQ
+	// MHash is pHash's 576 bit Marr-Hildreth edge hash.
+	MHash
+	// RadialVariance is pHash's radial variance digest.
+	RadialVariance
+	// BHash is the blockhash.io block mean hash.
+	BHash
+	// PHashYCbCr is the pHash of each YCbCr plane.
+	PHashYCbCr
+	// PHashHSV is the pHash of each HSV plane.
+	PHashHSV
+	// ColorMoment is the colour moments of the YCbCr planes.
+	ColorMoment
+	// CropResistant is the pHashes of the regions of an image.
+	CropResistant
+)
+
+// kindPrefix is the tag of each Kind in the String form of a hash.
+var kindPrefix = map[Kind]string{
+	PHash:          "p",
+	AHash:          "a",
+	DHash:          "d",
+	DHashVertical:  "v",
+	WHash:          "w",
+	WHashD4:        "w4",
+	PDQ:            "pdq",
+	MHash:          "mh",
+	RadialVariance: "rv",
+	BHash:          "bh",
+	PHashYCbCr:     "pycc",
+	PHashHSV:       "phsv",
+	ColorMoment:    "cm",
+	CropResistant:  "cr",
+}
+
+func (k Kind) String() string {
+	switch k {
+	case PHash:
+		return "PHash"
+	case AHash:
+		return "AHash"
+	case DHash:
+		return "DHash"
+	case DHashVertical:
+		return "DHashVertical"
+	case WHash:
+		return "WHash"
+	case WHashD4:
+		return "WHashD4"
+	case PDQ:
+		return "PDQ"
+	case MHash:
+		return "MHash"
+	case RadialVariance:
+		return "RadialVariance"
+	case BHash:
+		return "BHash"
+	case PHashYCbCr:
+		return "PHashYCbCr"
+	case PHashHSV:
+		return "PHashHSV"
+	case ColorMoment:
+		return "ColorMoment"
+	case CropResistant:
+		return "CropResistant"
+	}
+	return "Unknown"
+}
+
+var (
+	// ErrKindMismatch is returned comparing hashes of different kinds.
+	ErrKindMismatch = errors.New("imagehash: hashes are of different kinds")
+	// ErrLengthMismatch is returned comparing hashes of different lengths.
+	ErrLengthMismatch = errors.New("imagehash: hashes are of different lengths")
+	// ErrNilImage is returned hashing a nil image.
+	ErrNilImage = errors.New("imagehash: image is nil")
+	// ErrEmptyImage is returned hashing an image without pixels.
+	ErrEmptyImage = errors.New("imagehash: image is empty")
+	// ErrInvalidHash is returned parsing a malformed hash string.
+	ErrInvalidHash = errors.New("imagehash: invalid hash string")
+)
+
+// ImageHash is a 64 bit perceptual hash tagged with its Kind. Bit 63 holds the
+// first pixel or coefficient compared, in row order.
+type ImageHash struct {
+	hash uint64
+	kind Kind
+}
+
+// NewImageHash returns an ImageHash of kind holding hash.
+func NewImageHash(hash uint64, kind Kind) *ImageHash {
+	return &ImageHash{hash: hash, kind: kind}
+}
+
+// GetHash returns the bits of h.
+func (h *ImageHash) GetHash() uint64 {
+	return h.hash
+}
+
+// GetKind returns the algorithm h was computed with.
+func (h *ImageHash) GetKind() Kind {
+	return h.kind
+}
+
+// Distance returns the Hamming distance between h and other, or
+// ErrKindMismatch if they were computed with different algorithms or either
+// is nil.
+func (h *ImageHash) Distance(other *ImageHash) (int, error) {
+	if h == nil || other == nil || h.kind != other.kind {
+		return -1, ErrKindMismatch
+	}
+	return bits.OnesCount64(h.hash ^ other.hash), nil
+}
+
+// String returns h as its kind tag and 16 hex digits, such as
+// "p:8f373714acfcf4d0".
+func (h *ImageHash) String() string {
+	prefix, ok := kindPrefix[h.kind]
+	if !ok {
+		prefix = "?"
+	}
+	return fmt.Sprintf("%s:%016x", prefix, h.hash)
+}
+
+// ParseImageHash parses the String form of an ImageHash.
+func ParseImageHash(s string) (*ImageHash, error) {
+	prefix, digits, ok := strings.Cut(s, ":")
+	if !ok || len(digits) != 16 {
+		return nil, ErrInvalidHash
+	}
+
+	for kind, p := range kindPrefix {
+		if p == prefix {
+			hash, err := strconv.ParseUint(digits, 16, 64)
+			if err != nil {
+				return nil, ErrInvalidHash
+			}
+			return NewImageHash(hash, kind), nil
+		}
+	}
+	return nil, ErrInvalidHash
+}
@@ -0,0 +1,1366 @@
+package imagehash
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/bits"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/bmp"
+
+	"go.local/go-image-phash/dct"
+)
+
+const (
+	EPSILON float64 = 0.00000001
+)
+
+// gradient returns a w x h gray image, dark to light from left to right, or
+// from top to bottom if vertical.
+func gradient(w, h int, vertical bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(255 * x / (w - 1))
+			if vertical {
+				v = uint8(255 * y / (h - 1))
+			}
+			img.Set(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+// noise returns a w x h image of random colours.
+func noise(w, h int, seed int64) *image.RGBA {
+	r := rand.New(rand.NewSource(seed))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+type hashFunc func(image.Image) (*ImageHash, error)
+
+func TestHashes(t *testing.T) {
+	horizontal := gradient(64, 64, false)
+	vertical := gradient(64, 64, true)
+
+	for _, tt := range []struct {
+		name string
+		hash hashFunc
+		kind Kind
+		img  image.Image
+		want uint64
+	}{
+		{"DifferenceHash", DifferenceHash, DHash, horizontal, 0xffffffffffffffff},
+		{"DifferenceHash", DifferenceHash, DHash, vertical, 0},
+		{"DifferenceHashVertical", DifferenceHashVertical, DHashVertical, vertical, 0xffffffffffffffff},
+		{"DifferenceHashVertical", DifferenceHashVertical, DHashVertical, horizontal, 0},
+		{"AverageHash", AverageHash, AHash, horizontal, 0x0f0f0f0f0f0f0f0f},
+		{"AverageHash", AverageHash, AHash, vertical, 0x00000000ffffffff},
+		{"WaveletHash", WaveletHash, WHash, horizontal, 0x0f0f0f0f0f0f0f0f},
+		{"WaveletHash", WaveletHash, WHash, vertical, 0x00000000ffffffff},
+		{"WaveletHashD4", WaveletHashD4, WHashD4, vertical, 0x00000000ffffffff},
+	} {
+		h, err := tt.hash(tt.img)
+		if err != nil {
+			t.Fatalf("%s returned error %v", tt.name, err)
+		}
+		if h.GetKind() != tt.kind {
+			t.Errorf("%s kind is %v, expected %v.", tt.name, h.GetKind(), tt.kind)
+		}
+		if h.GetHash() != tt.want {
+			t.Errorf("%s is %016x, expected %016x.", tt.name, h.GetHash(), tt.want)
+		}
+	}
+}
+
+func TestPerceptionHash(t *testing.T) {
+	img := noise(256, 256, 1)
+	h, err := PerceptionHash(img)
+	if err != nil {
+		t.Fatalf("PerceptionHash returned error %v", err)
+	}
+	if h.GetKind() != PHash {
+		t.Errorf("PerceptionHash kind is %v, expected %v.", h.GetKind(), PHash)
+	}
+
+	// the median splits the 64 coefficients in half
+	if ones := bits.OnesCount64(h.GetHash()); ones != 32 {
+		t.Errorf("PerceptionHash has %d bits set, expected 32.", ones)
+	}
+
+	// a brighter copy keeps the hash, another image does not
+	brighter := image.NewRGBA(img.Bounds())
+	for i, p := range img.Pix {
+		brighter.Pix[i] = p
+		if i%4 != 3 {
+			brighter.Pix[i] = uint8(min(255, int(p)*9/10+20))
+		}
+	}
+	for _, tt := range []struct {
+		img     image.Image
+		maxDist int
+		minDist int
+	}{
+		{brighter, 4, 0},
+		{noise(256, 256, 2), 64, 16},
+	} {
+		other, err := PerceptionHash(tt.img)
+		if err != nil {
+			t.Fatalf("PerceptionHash returned error %v", err)
+		}
+		d, err := h.Distance(other)
+		if err != nil {
+			t.Fatalf("Distance returned error %v", err)
+		}
+		if d < tt.minDist || d > tt.maxDist {
+			t.Errorf("Distance is %d, expected %d to %d.", d, tt.minDist, tt.maxDist)
+		}
+	}
+}
+
+// TestPerceptionHashInt checks the integer DCT pHash on the fixtures in
+// testdata. Against the float DCT of the same 8 bit grayscale, a bit may only
+// differ where the coefficient is within twice dct.IntDCTErrorBound of the
+// median, once for the coefficient and once for the median. Textured images
+// give the bits of PerceptionHash itself.
+func TestPerceptionHashInt(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.png"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+	for _, f := range files {
+		name, _ := filepath.Rel("testdata", f)
+		img := fixture(t, name)
+		h, err := PerceptionHashInt(img)
+		if err != nil {
+			t.Fatalf("PerceptionHashInt of %s returned error %v", name, err)
+		}
+
+		pixels := grayscale(img, pHashSize, pHashSize)
+		for i, v := range pixels {
+			pixels[i] = math.Round(v)
+		}
+		coef, err := dct.PartialDCT2D(pixels, pHashSize, pHashCorner)
+		if err != nil {
+			t.Fatalf("PartialDCT2D returned error %v", err)
+		}
+		median := medianOf(coef)
+		diff := h.GetHash() ^ hashAbove(coef, median)
+		for i, c := range coef {
+			if diff>>(63-i)&1 == 1 && math.Abs(c-median) > 2*dct.IntDCTErrorBound {
+				t.Errorf("PerceptionHashInt of %s differs in bit %d, %v from the median %v.", name, i, c, median)
+			}
+		}
+	}
+
+	for _, name := range []string{"pdq/noise-96x96.png", "pdq/shapes-257x129.png", "blockhash/noise-37x29.png", "blockhash/shapes-150x100.png", "blockhash/cutout-90x70.png"} {
+		img := fixture(t, name)
+		want, _ := PerceptionHash(img)
+		if h, err := PerceptionHashInt(img); err != nil || h.GetHash() != want.GetHash() {
+			t.Errorf("PerceptionHashInt of %s is %v, %v, expected %v.", name, h, err, want)
+		}
+	}
+
+	if _, err := PerceptionHashInt(nil); err != ErrNilImage {
+		t.Errorf("PerceptionHashInt(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+	if _, err := PerceptionHashInt(image.NewRGBA(image.Rectangle{})); err != ErrEmptyImage {
+		t.Errorf("PerceptionHashInt of an empty image expected error %v but got %v.", ErrEmptyImage, err)
+	}
+}
+
+// TestWaveletHash checks the Haar wHash against what Python's whash reduces
+// to: the Haar approximation is a scaled block mean, and taking out the
+// coarsest one shifts every block alike, so the hash is the 8x8 block means
+// of the grayscale against their median.
+func TestWaveletHash(t *testing.T) {
+	for _, size := range []int{8, 64, 200} {
+		img := noise(size, size+17, int64(size))
+		h, err := WaveletHash(img)
+		if err != nil {
+			t.Fatalf("WaveletHash returned error %v", err)
+		}
+
+		scale := max(8, 1<<(bits.Len(uint(size))-1))
+		block := scale / 8
+		pixels := grayscale(img, scale, scale)
+		means := make([]float64, 64)
+		for y := 0; y < scale; y++ {
+			for x := 0; x < scale; x++ {
+				means[(y/block)*8+x/block] += pixels[y*scale+x]
+			}
+		}
+		if want := hashAbove(means, medianOf(means)); h.GetHash() != want {
+			t.Errorf("WaveletHash of %d is %016x, expected %016x.", size, h.GetHash(), want)
+		}
+	}
+
+	if _, err := WaveletHash(image.NewRGBA(image.Rect(0, 0, 0, 0))); err != ErrEmptyImage {
+		t.Errorf("WaveletHash of an empty image expected error %v but got %v.", ErrEmptyImage, err)
+	}
+}
+
+// second returns the error of a value and error pair.
+func second[T any](_ T, err error) error {
+	return err
+}
+
+func TestDistance(t *testing.T) {
+	img := noise(64, 64, 3)
+	hashes := map[Kind]*ImageHash{}
+	for kind, hash := range map[Kind]hashFunc{
+		PHash:         PerceptionHash,
+		AHash:         AverageHash,
+		DHash:         DifferenceHash,
+		DHashVertical: DifferenceHashVertical,
+		WHash:         WaveletHash,
+		WHashD4:       WaveletHashD4,
+	} {
+		h, err := hash(img)
+		if err != nil {
+			t.Fatalf("%v returned error %v", kind, err)
+		}
+		if d, err := h.Distance(h); err != nil || d != 0 {
+			t.Errorf("%v distance to itself is %d, %v.", kind, d, err)
+		}
+		hashes[kind] = h
+	}
+
+	// equal bits are still never compared across kinds
+	for a := range hashes {
+		for b := range hashes {
+			if a == b {
+				continue
+			}
+			other := NewImageHash(hashes[a].GetHash(), b)
+			if d, err := hashes[a].Distance(other); !errors.Is(err, ErrKindMismatch) {
+				t.Errorf("Distance of %v to %v is %d, %v, expected %v.", a, b, d, err, ErrKindMismatch)
+			}
+		}
+	}
+
+	// nil hashes compare as no kind, whichever side they are on
+	var nilHash *ImageHash
+	ext := NewExtImageHash(make([]uint64, 4), PDQ)
+	radial := NewRadialDigest(make([]uint8, 8))
+	moments := NewColorMoments([9]float64{})
+	multi := NewMultiHash(nil)
+	for name, err := range map[string]error{
+		"ImageHash":               second(hashes[PHash].Distance(nil)),
+		"nil ImageHash":           second(nilHash.Distance(hashes[PHash])),
+		"ExtImageHash":            second(ext.Distance(nil)),
+		"ExtImageHash normalized": second(ext.NormalizedDistance(nil)),
+		"RadialDigest":            second(radial.CrossCorrelation(nil)),
+		"ColorMoments":            second(moments.Distance(nil)),
+		"MultiHash":               second(multi.Distance(nil)),
+	} {
+		if err != ErrKindMismatch {
+			t.Errorf("%s distance to nil expected error %v but got %v.", name, ErrKindMismatch, err)
+		}
+	}
+
+	if _, err := AverageHash(nil); err != ErrNilImage {
+		t.Errorf("AverageHash(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+
+	// an all zero hash would match, so an image without pixels is an error
+	empty := image.NewRGBA(image.Rect(3, 3, 3, 3))
+	for kind, hash := range map[Kind]hashFunc{
+		PHash:         PerceptionHash,
+		AHash:         AverageHash,
+		DHash:         DifferenceHash,
+		DHashVertical: DifferenceHashVertical,
+	} {
+		if h, err := hash(empty); err != ErrEmptyImage {
+			t.Errorf("%v of an empty image is %v, expected error %v but got %v.", kind, h, ErrEmptyImage, err)
+		}
+	}
+}
+
+func TestParseImageHash(t *testing.T) {
+	for _, kind := range []Kind{PHash, AHash, DHash, DHashVertical, WHash, WHashD4} {
+		h := NewImageHash(0x8f373714acfcf4d0, kind)
+		parsed, err := ParseImageHash(h.String())
+		if err != nil {
+			t.Fatalf("ParseImageHash(%q) returned error %v", h.String(), err)
+		}
+		if *parsed != *h {
+			t.Errorf("ParseImageHash(%q) is %v, expected %v.", h.String(), parsed, h)
+		}
+	}
+
+	for _, s := range []string{"", "p", "p:123", "x:8f373714acfcf4d0", "p:8f373714acfcf4dg"} {
+		if _, err := ParseImageHash(s); err != ErrInvalidHash {
+			t.Errorf("ParseImageHash(%q) expected error %v but got %v.", s, ErrInvalidHash, err)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	img := noise(16, 16, 4)
+	for _, tt := range []struct {
+		contentType string
+		encode      func(*bytes.Buffer) error
+	}{
+		{"image/bmp", func(b *bytes.Buffer) error { return bmp.Encode(b, img) }},
+		{"image/gif", func(b *bytes.Buffer) error { return gif.Encode(b, img, nil) }},
+		{"image/jpeg", func(b *bytes.Buffer) error { return jpeg.Encode(b, img, nil) }},
+		{"image/png", func(b *bytes.Buffer) error { return png.Encode(b, img) }},
+	} {
+		var buf bytes.Buffer
+		if err := tt.encode(&buf); err != nil {
+			t.Fatalf("encoding %s returned error %v", tt.contentType, err)
+		}
+		decoded, contentType, err := Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("Decode of %s returned error %v", tt.contentType, err)
+		}
+		if contentType != tt.contentType || decoded.Bounds() != img.Bounds() {
+			t.Errorf("Decode of %s gave %s, %v.", tt.contentType, contentType, decoded.Bounds())
+		}
+	}
+
+	if _, _, err := Decode(bytes.NewReader([]byte("not an image"))); err != ErrUnsupportedType {
+		t.Errorf("Decode of text expected error %v but got %v.", ErrUnsupportedType, err)
+	}
+
+	for path, want := range map[string]string{
+		"a.BMP": "image/bmp", "a.jpg": "image/jpeg", "a.jpeg": "image/jpeg",
+		"a.webp": "image/webp", "a.txt": "",
+	} {
+		if got := ContentTypeByExt(path); got != want {
+			t.Errorf("ContentTypeByExt(%q) is %q, expected %q.", path, got, want)
+		}
+	}
+}
+
+// dihedral returns the square img transformed by d.
+func dihedral(img *image.RGBA, d Dihedral) *image.RGBA {
+	n := img.Bounds().Dx() - 1
+	out := image.NewRGBA(img.Bounds())
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			sx, sy := x, y
+			switch d {
+			case Rotate90:
+				sx, sy = n-y, x
+			case Rotate180:
+				sx, sy = n-x, n-y
+			case Rotate270:
+				sx, sy = y, n-x
+			case FlipX:
+				sy = n - y
+			case FlipY:
+				sx = n - x
+			case FlipPlus1:
+				sx, sy = y, x
+			case FlipMinus1:
+				sx, sy = n-y, n-x
+			}
+			out.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return out
+}
+
+func TestPDQDihedral(t *testing.T) {
+	// at 64x64 the filter and the decimation are the identity, so each
+	// variant must be the hash of the transformed image
+	img := noise(64, 64, 5)
+	hashes, quality, err := PDQHashDihedral(img)
+	if err != nil {
+		t.Fatalf("PDQHashDihedral returned error %v", err)
+	}
+	if quality != 100 {
+		t.Errorf("PDQ quality of noise is %d, expected 100.", quality)
+	}
+
+	for i, d := range Dihedrals {
+		h, q, err := PDQHash(dihedral(img, d))
+		if err != nil {
+			t.Fatalf("PDQHash returned error %v", err)
+		}
+		if dist, err := h.Distance(hashes[i]); err != nil || dist != 0 {
+			t.Errorf("PDQ %v variant is %d from the hash of the %v image, %v.", d, dist, d, err)
+		}
+		if q != quality {
+			t.Errorf("PDQ quality of the %v image is %d, expected %d.", d, q, quality)
+		}
+
+		ones := 0
+		for _, w := range h.GetHash() {
+			ones += bits.OnesCount64(w)
+		}
+		if h.Bits() != 256 || ones != 128 {
+			t.Errorf("PDQ %v hash has %d of %d bits set, expected 128 of 256.", d, ones, h.Bits())
+		}
+	}
+
+	// larger images are filtered and decimated, which is only nearly
+	// symmetric
+	big := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			v := 128 + 60*math.Sin(float64(x)/23) + 60*math.Cos(float64(x+2*y)/37)
+			big.SetRGBA(x, y, color.RGBA{uint8(v), uint8(255 - v), uint8(v / 2), 255})
+		}
+	}
+	hashes, _, err = PDQHashDihedral(big)
+	if err != nil {
+		t.Fatalf("PDQHashDihedral returned error %v", err)
+	}
+	for i, d := range Dihedrals {
+		h, _, err := PDQHash(dihedral(big, d))
+		if err != nil {
+			t.Fatalf("PDQHash returned error %v", err)
+		}
+		if dist, _ := h.Distance(hashes[i]); dist > 16 {
+			t.Errorf("PDQ %v variant of 300x300 is %d from the hash of the %v image.", d, dist, d)
+		}
+	}
+}
+
+func TestPDQ(t *testing.T) {
+	flat := image.NewRGBA(image.Rect(0, 0, 100, 80))
+	for i := range flat.Pix {
+		flat.Pix[i] = 77
+	}
+	if _, quality, err := PDQHash(flat); err != nil || quality != 0 {
+		t.Errorf("PDQ quality of a flat image is %d, %v, expected 0.", quality, err)
+	}
+
+	h, _, err := PDQHash(noise(640, 480, 6))
+	if err != nil {
+		t.Fatalf("PDQHash returned error %v", err)
+	}
+	s := h.String()
+	if len(s) != len("pdq:")+64 || s[:4] != "pdq:" {
+		t.Errorf("PDQ String is %q, expected pdq: and 64 hex digits.", s)
+	}
+	parsed, err := ParseExtImageHash(s)
+	if err != nil {
+		t.Fatalf("ParseExtImageHash(%q) returned error %v", s, err)
+	}
+	if d, err := parsed.Distance(h); err != nil || d != 0 || parsed.String() != s {
+		t.Errorf("ParseExtImageHash(%q) is %v, %d from the hash, %v.", s, parsed, d, err)
+	}
+
+	if _, err := h.Distance(NewExtImageHash(h.GetHash(), WHash)); err != ErrKindMismatch {
+		t.Errorf("Distance across kinds expected error %v but got %v.", ErrKindMismatch, err)
+	}
+	if _, err := h.Distance(NewExtImageHash(h.GetHash()[:2], PDQ)); err != ErrLengthMismatch {
+		t.Errorf("Distance across lengths expected error %v but got %v.", ErrLengthMismatch, err)
+	}
+	for _, s := range []string{"pdq:", "pdq:123", "x:0123456789abcdef", "pdq:0123456789abcdeg"} {
+		if _, err := ParseExtImageHash(s); err != ErrInvalidHash {
+			t.Errorf("ParseExtImageHash(%q) expected error %v but got %v.", s, ErrInvalidHash, err)
+		}
+	}
+
+	if _, _, err := PDQHash(nil); err != ErrNilImage {
+		t.Errorf("PDQHash(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+}
+
+// fixture decodes the image at path under testdata.
+func fixture(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", path))
+	if err != nil {
+		t.Fatalf("opening fixture: %v", err)
+	}
+	defer f.Close()
+	img, _, err := Decode(f)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+	return img
+}
+
+// records returns the comma separated fields of each line of the file at
+// path under testdata.
+func records(t *testing.T, path string) [][]string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", path))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var out [][]string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		out = append(out, strings.Split(line, ","))
+	}
+	return out
+}
+
+// TestPDQReference checks PDQ against the expectations in testdata/pdq, in
+// the reference pdq-photo-hasher's hash,quality,filename format. They come
+// from reference.py there, a float32 transcription of the reference.
+func TestPDQReference(t *testing.T) {
+	for _, r := range records(t, "pdq/hashes.csv") {
+		img := fixture(t, "pdq/"+r[2])
+		h, quality, err := PDQHash(img)
+		if err != nil {
+			t.Fatalf("PDQHash of %s returned error %v", r[2], err)
+		}
+		if h.String() != "pdq:"+r[0] || strconv.Itoa(quality) != r[1] {
+			t.Errorf("PDQHash of %s is %v, quality %d, expected pdq:%s, quality %s.", r[2], h, quality, r[0], r[1])
+		}
+	}
+
+	for _, r := range records(t, "pdq/dihedral.csv") {
+		hashes, _, err := PDQHashDihedral(fixture(t, "pdq/"+r[0]))
+		if err != nil {
+			t.Fatalf("PDQHashDihedral of %s returned error %v", r[0], err)
+		}
+		for i, d := range Dihedrals {
+			if hashes[i].String() != "pdq:"+r[i+1] {
+				t.Errorf("PDQHashDihedral of %s %v is %v, expected pdq:%s.", r[0], d, hashes[i], r[i+1])
+			}
+		}
+	}
+}
+
+// publishedRecords returns the records of the file at path under testdata,
+// skipping the test if it has not been checked in.
+func publishedRecords(t *testing.T, path string) [][]string {
+	t.Helper()
+	if _, err := os.Stat(filepath.Join("testdata", path)); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("testdata/%s is not checked in, see the README beside it.", path)
+	}
+	return records(t, path)
+}
+
+// TestPDQPublished checks PDQ against the hashes ThreatExchange publishes
+// for its sample images, in testdata/pdq/threatexchange. Hashes of lossless
+// images must match to the bit; JPEG decoders differ from the reference's,
+// so those of JPEGs may differ in a few bits and their quality by a little.
+func TestPDQPublished(t *testing.T) {
+	for _, r := range publishedRecords(t, "pdq/threatexchange/hashes.csv") {
+		h, quality, err := PDQHash(fixture(t, "pdq/threatexchange/"+r[2]))
+		if err != nil {
+			t.Fatalf("PDQHash of %s returned error %v", r[2], err)
+		}
+		expected, err := ParseExtImageHash("pdq:" + r[0])
+		if err != nil {
+			t.Fatalf("parsing the hash of %s: %v", r[2], err)
+		}
+		want, _ := strconv.Atoi(r[1])
+
+		distance, qualitySlack := 0, 0
+		if !strings.HasSuffix(r[2], ".png") {
+			distance, qualitySlack = 8, 2
+		}
+		if d, _ := h.Distance(expected); d > distance || quality < want-qualitySlack || quality > want+qualitySlack {
+			t.Errorf("PDQHash of %s is %v, quality %d, expected %v, quality %d.", r[2], h, quality, expected, want)
+		}
+	}
+}
+
+func TestBox1D(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for _, n := range []int{1, 2, 5, 64, 100} {
+		in := make([]float32, n)
+		for i := range in {
+			in[i] = r.Float32()
+		}
+		for window := 1; window <= n && window <= 9; window++ {
+			out := make([]float32, n)
+			box1D(in, out, n, 1, window)
+
+			// the window covers o - (window - half) to o + half - 1
+			half := (window + 2) / 2
+			for o := range out {
+				lo, hi := max(0, o-(window-half)), min(n-1, o+half-1)
+				var sum float64
+				for i := lo; i <= hi; i++ {
+					sum += float64(in[i])
+				}
+				if expect := sum / float64(hi-lo+1); math.Abs(float64(out[o])-expect) > 1e-6 {
+					t.Fatalf("box1D(%d, %d)[%d] is %v, expected %v.", n, window, o, out[o], expect)
+				}
+			}
+		}
+	}
+
+	for n, window := range map[int]int{1: 1, 64: 1, 127: 1, 128: 1, 129: 2, 1000: 8, 1024: 8} {
+		if got := jaroszWindowSize(n); got != window {
+			t.Errorf("jaroszWindowSize(%d) is %d, expected %d.", n, got, window)
+		}
+	}
+}
+
+// shape returns a size x size image of a dark shape on white, scaled to the
+// image, "circle", "square" or "triangle", moved right by shift pixels.
+func shape(kind string, size, shift int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	s := float64(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			u, v := (float64(x-shift)+0.5)/s-0.5, (float64(y)+0.5)/s-0.5
+			inside := false
+			switch kind {
+			case "circle":
+				inside = u*u+v*v < 0.3*0.3
+			case "square":
+				inside = math.Abs(u) < 0.28 && math.Abs(v) < 0.28
+			case "triangle":
+				inside = v < 0.3 && v > -0.3 && math.Abs(u) < (v+0.3)/1.2
+			}
+			c := uint8(255)
+			if inside {
+				c = 30
+			}
+			img.SetRGBA(x, y, color.RGBA{c, c, c, 255})
+		}
+	}
+	return img
+}
+
+func TestMarrHildrethHash(t *testing.T) {
+	hashes := map[string]*ExtImageHash{}
+	for _, kind := range []string{"circle", "square", "triangle"} {
+		h, err := MarrHildrethHash(shape(kind, 300, 0))
+		if err != nil {
+			t.Fatalf("MarrHildrethHash returned error %v", err)
+		}
+		if h.GetKind() != MHash || h.Bits() != 576 {
+			t.Fatalf("MarrHildrethHash is %v of %d bits, expected %v of 576.", h.GetKind(), h.Bits(), MHash)
+		}
+		hashes[kind] = h
+	}
+
+	// the same shape drawn larger or nudged stays closer than any other
+	for _, kind := range []string{"circle", "square", "triangle"} {
+		for _, img := range []*image.RGBA{shape(kind, 450, 0), shape(kind, 300, 3)} {
+			h, err := MarrHildrethHash(img)
+			if err != nil {
+				t.Fatalf("MarrHildrethHash returned error %v", err)
+			}
+			same, _ := h.NormalizedDistance(hashes[kind])
+			if same > 0.1 {
+				t.Errorf("%v %v is %v from the %v, expected at most 0.1.", kind, img.Bounds().Size(), same, kind)
+			}
+			for other, oh := range hashes {
+				if other == kind {
+					continue
+				}
+				if d, _ := h.NormalizedDistance(oh); d < 0.15 {
+					t.Errorf("%v %v is %v from the %v, expected at least 0.15.", kind, img.Bounds().Size(), d, other)
+				}
+			}
+		}
+	}
+
+	// flat regions give 0 bits, not the rounding of their mean
+	blank := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := range blank.Pix {
+		blank.Pix[i] = 255
+	}
+	if h, err := MarrHildrethHash(blank); err != nil || h.String() != "mh:"+strings.Repeat("0", 144) {
+		t.Errorf("MarrHildrethHash of a blank image is %v, %v, expected all 0.", h, err)
+	}
+
+	if d, err := hashes["circle"].NormalizedDistance(hashes["circle"]); err != nil || d != 0 {
+		t.Errorf("NormalizedDistance to itself is %v, %v.", d, err)
+	}
+	if _, err := MarrHildrethHash(nil); err != ErrNilImage {
+		t.Errorf("MarrHildrethHash(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+}
+
+// ellipse is an axis-aligned ellipse, in fractions of the image side.
+type ellipse struct{ cx, cy, rx, ry float64 }
+
+func (e ellipse) contains(u, v float64) bool {
+	du, dv := (u-e.cx)/e.rx, (v-e.cy)/e.ry
+	return du*du+dv*dv < 1
+}
+
+// randomEllipses returns n ellipses from r, centred in [offset, offset+1)
+// with radii in [radius, radius+spread), each with a gray shade below
+// shades.
+func randomEllipses(r *rand.Rand, n int, offset, radius, spread float64, shades int) ([]ellipse, []uint8) {
+	ellipses := make([]ellipse, n)
+	grays := make([]uint8, n)
+	for i := range ellipses {
+		ellipses[i] = ellipse{r.Float64() + offset, r.Float64() + offset, radius + spread*r.Float64(), radius + spread*r.Float64()}
+		grays[i] = uint8(r.Intn(shades))
+	}
+	return ellipses, grays
+}
+
+// paint returns a size x size image coloured by at, given the centre of
+// each pixel, shifted right by shift pixels, in fractions of size.
+func paint(size, shift int, at func(u, v float64) color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	s := float64(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, at((float64(x-shift)+0.5)/s, (float64(y)+0.5)/s))
+		}
+	}
+	return img
+}
+
+// scene returns a size x size image of random ellipses inside a disc on a
+// gray background, rotated by deg degrees about its centre, so rotation
+// moves no content in or out of the frame.
+func scene(size int, seed int64, deg float64) *image.RGBA {
+	ellipses, shades := randomEllipses(rand.New(rand.NewSource(seed)), 6, -0.5, 0.05, 0.2, 256)
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	return paint(size, 0, func(u, v float64) color.RGBA {
+		u0, v0 := u-0.5, v-0.5
+		u, v = cos*u0-sin*v0, sin*u0+cos*v0
+		c := uint8(128)
+		for i, e := range ellipses {
+			if u0*u0+v0*v0 < 0.25 && e.contains(u, v) {
+				c = shades[i]
+			}
+		}
+		return color.RGBA{c, c, c, 255}
+	})
+}
+
+func TestRadialVarianceHash(t *testing.T) {
+	for seed := int64(1); seed <= 4; seed++ {
+		base, err := RadialVarianceHash(scene(200, seed, 0))
+		if err != nil {
+			t.Fatalf("RadialVarianceHash returned error %v", err)
+		}
+		if base.GetKind() != RadialVariance || len(base.GetHash()) != 40 {
+			t.Fatalf("RadialVarianceHash is %v of %d coefficients, expected %v of 40.", base.GetKind(), len(base.GetHash()), RadialVariance)
+		}
+
+		// the same scene larger or turned slightly matches
+		for _, img := range []*image.RGBA{scene(300, seed, 0), scene(200, seed, 3)} {
+			h, err := RadialVarianceHash(img)
+			if err != nil {
+				t.Fatalf("RadialVarianceHash returned error %v", err)
+			}
+			if c, _ := base.CrossCorrelation(h); c <= RadialThreshold {
+				t.Errorf("scene %d %v correlates %v with itself, expected above %v.", seed, img.Bounds().Size(), c, RadialThreshold)
+			}
+		}
+
+		// other scenes and shapes do not
+		for _, img := range []*image.RGBA{scene(200, seed+10, 0), scene(200, seed+20, 0), shape("circle", 200, 0)} {
+			h, err := RadialVarianceHash(img)
+			if err != nil {
+				t.Fatalf("RadialVarianceHash returned error %v", err)
+			}
+			if c, _ := base.CrossCorrelation(h); c > RadialThreshold {
+				t.Errorf("scene %d correlates %v with another image, expected at most %v.", seed, c, RadialThreshold)
+			}
+		}
+
+		if c, err := base.CrossCorrelation(base); err != nil || math.Abs(c-1) > EPSILON {
+			t.Errorf("CrossCorrelation to itself is %v, %v.", c, err)
+		}
+		parsed, err := ParseRadialDigest(base.String())
+		if err != nil || parsed.String() != base.String() {
+			t.Errorf("ParseRadialDigest(%q) is %v, %v.", base.String(), parsed, err)
+		}
+	}
+
+	// a flat image has no variance to standardise and correlates with nothing
+	flat := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for i := range flat.Pix {
+		flat.Pix[i] = 77
+	}
+	blank, err := RadialVarianceHash(flat)
+	if err != nil {
+		t.Fatalf("RadialVarianceHash returned error %v", err)
+	}
+	if blank.String() != "rv:"+strings.Repeat("0", 80) {
+		t.Errorf("RadialVarianceHash of a flat image is %v, expected all 0.", blank)
+	}
+	if c, err := blank.CrossCorrelation(blank); err != nil || c != 0 {
+		t.Errorf("CrossCorrelation of flat digests is %v, %v, expected 0.", c, err)
+	}
+
+	if _, err := blank.CrossCorrelation(NewRadialDigest(make([]uint8, 8))); err != ErrLengthMismatch {
+		t.Errorf("CrossCorrelation of different lengths expected error %v but got %v.", ErrLengthMismatch, err)
+	}
+	for _, s := range []string{"rv:", "rv:0g", "p:00", "00"} {
+		if _, err := ParseRadialDigest(s); err != ErrInvalidHash {
+			t.Errorf("ParseRadialDigest(%q) expected error %v but got %v.", s, ErrInvalidHash, err)
+		}
+	}
+	if _, err := RadialVarianceHash(nil); err != ErrNilImage {
+		t.Errorf("RadialVarianceHash(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+}
+
+func TestBlockHash(t *testing.T) {
+	// left columns black, the rest white
+	split := func(w, h, black int) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				c := color.RGBA{255, 255, 255, 255}
+				if x < black {
+					c = color.RGBA{0, 0, 0, 255}
+				}
+				img.SetRGBA(x, y, c)
+			}
+		}
+		return img
+	}
+	uniform := func(w, h int, c color.RGBA) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+		return img
+	}
+
+	rows := func(row string, n int) string { return "bh:" + strings.Repeat(row, n) }
+	for _, tt := range []struct {
+		name  string
+		img   image.Image
+		bits  int
+		hash  string
+		quick string // "" if the same as hash
+	}{
+		{"16x16 halves", split(16, 16, 8), 8, rows("0f", 8), ""},
+		// blocks of 2.25 pixels, the boundary on a pixel edge; the quick
+		// hash's 2 pixel blocks put a gray block at the boundary
+		{"18x18 halves", split(18, 18, 9), 8, rows("0f", 8), ""},
+		// the quick hash drops the white column left over
+		{"17x16 white edge", split(17, 16, 16), 8, rows("01", 8), rows("00", 8)},
+		{"13x11 gradient", gradient(13, 11, false), 8, rows("0f", 8), ""},
+		// each quarter, 4 rows of 16 bits, is split at its own median
+		{"32x32 vertical gradient", gradient(32, 32, true), 16, rows("00000000ffffffff", 4), ""},
+		// equal blocks are set only if brighter than half white
+		{"12x12 white", uniform(12, 12, color.RGBA{255, 255, 255, 255}), 8, rows("ff", 8), ""},
+		{"12x12 black", uniform(12, 12, color.RGBA{0, 0, 0, 255}), 8, rows("00", 8), ""},
+		{"12x12 transparent", uniform(12, 12, color.RGBA{}), 8, rows("ff", 8), ""},
+	} {
+		h, err := BlockHash(tt.img, tt.bits)
+		if err != nil {
+			t.Fatalf("%s: BlockHash returned error %v", tt.name, err)
+		}
+		if h.String() != tt.hash {
+			t.Errorf("%s: BlockHash is %v, expected %v.", tt.name, h, tt.hash)
+		}
+
+		quick := tt.quick
+		if quick == "" {
+			quick = tt.hash
+		}
+		if h, err := BlockHashQuick(tt.img, tt.bits); err != nil || h.String() != quick {
+			t.Errorf("%s: BlockHashQuick is %v, %v, expected %v.", tt.name, h, err, quick)
+		}
+	}
+
+	// partners send the reference's plain hex
+	h, _ := BlockHash(split(16, 16, 8), 8)
+	parsed, err := ParseBlockHash("0f0f0f0f0f0f0f0f")
+	if err != nil {
+		t.Fatalf("ParseBlockHash returned error %v", err)
+	}
+	if d, err := parsed.Distance(h); err != nil || d != 0 {
+		t.Errorf("ParseBlockHash is %v from the hash, %v.", d, err)
+	}
+	for _, s := range []string{"", "0f0f", "0f0f0f0f0f0f0f0g", "bh:0f0f0f0f0f0f0f0f", strings.Repeat("0f", 16)} {
+		if _, err := ParseBlockHash(s); err != ErrInvalidHash {
+			t.Errorf("ParseBlockHash(%q) expected error %v but got %v.", s, ErrInvalidHash, err)
+		}
+	}
+
+	for _, bits := range []int{0, 4, 12, 24, 32} {
+		if _, err := BlockHash(split(16, 16, 8), bits); err != ErrBlockHashBits {
+			t.Errorf("BlockHash with %d bits expected error %v but got %v.", bits, ErrBlockHashBits, err)
+		}
+	}
+	if _, err := BlockHashQuick(nil, 8); err != ErrNilImage {
+		t.Errorf("BlockHashQuick(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+}
+
+// TestBlockHashReference checks BlockHash and BlockHashQuick against the
+// expectations in testdata/blockhash, filename and the precise and quick
+// hashes with 8 and with 16 bits. They come from reference.py there, a
+// transcription of blockhash-python.
+func TestBlockHashReference(t *testing.T) {
+	for _, r := range records(t, "blockhash/hashes.csv") {
+		img := fixture(t, "blockhash/"+r[0])
+		for i, bits := range []int{8, 16} {
+			h, err := BlockHash(img, bits)
+			if err != nil || h.String() != "bh:"+r[1+2*i] {
+				t.Errorf("BlockHash of %s with %d bits is %v, %v, expected bh:%s.", r[0], bits, h, err, r[1+2*i])
+			}
+			h, err = BlockHashQuick(img, bits)
+			if err != nil || h.String() != "bh:"+r[2+2*i] {
+				t.Errorf("BlockHashQuick of %s with %d bits is %v, %v, expected bh:%s.", r[0], bits, h, err, r[2+2*i])
+			}
+		}
+	}
+}
+
+// TestBlockHashPublished checks BlockHash and BlockHashQuick against the
+// hashes blockhash-python and blockhash-js publish for their test images, in
+// testdata/blockhash/published, one filename,bits,method,hash line each with
+// method precise or quick. JPEG decoders differ from PIL's, so the hashes of
+// JPEGs may differ in a bit for every 4 of bits.
+func TestBlockHashPublished(t *testing.T) {
+	hashers := map[string]func(image.Image, int) (*ExtImageHash, error){
+		"precise": BlockHash,
+		"quick":   BlockHashQuick,
+	}
+	for _, r := range publishedRecords(t, "blockhash/published/hashes.csv") {
+		bits, err := strconv.Atoi(r[1])
+		if err != nil || hashers[r[2]] == nil {
+			t.Fatalf("bad line for %s: %v", r[0], r)
+		}
+		expected, err := ParseBlockHash(r[3])
+		if err != nil {
+			t.Fatalf("parsing the hash of %s: %v", r[0], err)
+		}
+
+		h, err := hashers[r[2]](fixture(t, "blockhash/published/"+r[0]), bits)
+		if err != nil {
+			t.Fatalf("%s hash of %s returned error %v", r[2], r[0], err)
+		}
+		distance := 0
+		if !strings.HasSuffix(r[0], ".png") {
+			distance = bits / 4
+		}
+		if d, _ := h.Distance(expected); d > distance {
+			t.Errorf("%s hash of %s with %d bits is %v, expected %v.", r[2], r[0], bits, h, expected)
+		}
+	}
+}
+
+// product returns a size x size product shot: a body in colour c with gray
+// details from seed on a light gradient, shifted right by shift pixels.
+func product(seed int64, size, shift int, c color.RGBA) *image.RGBA {
+	r := rand.New(rand.NewSource(seed))
+	body := ellipse{0.35 + 0.3*r.Float64(), 0.35 + 0.3*r.Float64(), 0.2 + 0.1*r.Float64(), 0.2 + 0.15*r.Float64()}
+	details, shades := randomEllipses(r, 3, 0, 0.03, 0.1, 200)
+
+	return paint(size, shift, func(u, v float64) color.RGBA {
+		g := uint8(200 + 55*v)
+		px := color.RGBA{g, g, g, 255}
+		if body.contains(u, v) {
+			px = c
+		}
+		for i, e := range details {
+			if e.contains(u, v) {
+				px = color.RGBA{shades[i], shades[i], shades[i], 255}
+			}
+		}
+		return px
+	})
+}
+
+func TestColorHashes(t *testing.T) {
+	colors := map[string]color.RGBA{
+		"red":    {200, 30, 30, 255},
+		"blue":   {30, 40, 200, 255},
+		"green":  {40, 160, 50, 255},
+		"navy":   {20, 20, 90, 255},
+		"orange": {230, 120, 20, 255},
+		"gray":   {100, 100, 100, 255},
+	}
+	compare := func(a, b image.Image) ColorDistance {
+		ha, err := ColorPerceptionHash(a, YCbCr)
+		if err != nil {
+			t.Fatalf("ColorPerceptionHash returned error %v", err)
+		}
+		hb, _ := ColorPerceptionHash(b, YCbCr)
+		ma, err := ColorMomentHash(a)
+		if err != nil {
+			t.Fatalf("ColorMomentHash returned error %v", err)
+		}
+		mb, _ := ColorMomentHash(b)
+		d, err := CompareColor(ha, hb, ma, mb)
+		if err != nil {
+			t.Fatalf("CompareColor returned error %v", err)
+		}
+		return d
+	}
+	recompress := func(img image.Image) image.Image {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 70}); err != nil {
+			t.Fatalf("jpeg.Encode returned error %v", err)
+		}
+		out, err := jpeg.Decode(&buf)
+		if err != nil {
+			t.Fatalf("jpeg.Decode returned error %v", err)
+		}
+		return out
+	}
+
+	// the verdicts hold away from the default thresholds: 2 bits of luma
+	// and a factor of 2 in the moments on the right side
+	def := DefaultColorThresholds
+	for seed := int64(1); seed <= 3; seed++ {
+		for name, c := range colors {
+			base := product(seed, 300, 0, c)
+			for _, dup := range []image.Image{product(seed, 450, 0, c), product(seed, 300, 3, c), recompress(base)} {
+				d := compare(base, dup)
+				if d.Verdict() != Duplicate || d.Luma > def.Luma-2 || d.Chroma > def.Chroma-2 || d.Moments > def.Moments/2 {
+					t.Errorf("%d %s %v: %+v is %v, expected %v within the margins.", seed, name, dup.Bounds().Size(), d, d.Verdict(), Duplicate)
+				}
+			}
+			for other, oc := range colors {
+				if other == name {
+					continue
+				}
+				d := compare(base, product(seed, 300, 0, oc))
+				if d.Verdict() != ColorVariant || d.Luma > def.Luma-2 || d.Moments < def.Moments*2 {
+					t.Errorf("%d %s against %s: %+v is %v, expected %v within the margins.", seed, name, other, d, d.Verdict(), ColorVariant)
+				}
+			}
+			for other := int64(1); other <= 3; other++ {
+				if other == seed {
+					continue
+				}
+				d := compare(base, product(other, 300, 0, c))
+				if d.Verdict() != Different || d.Luma < def.Luma+2 {
+					t.Errorf("%d %s against %d: %+v is %v, expected %v within the margins.", seed, name, other, d, d.Verdict(), Different)
+				}
+			}
+		}
+	}
+
+	// thresholds of the caller's own
+	strict := ColorThresholds{Luma: 4, Chroma: 2, Moments: 0.005}
+	for _, tt := range []struct {
+		d               ColorDistance
+		verdict, strict ColorVerdict
+	}{
+		{ColorDistance{}, Duplicate, Duplicate},
+		{ColorDistance{Luma: 6, Chroma: 4, Moments: 0.01}, Duplicate, Different},
+		{ColorDistance{Luma: 2, Chroma: 4, Moments: 0.001}, Duplicate, ColorVariant},
+		{ColorDistance{Luma: 2, Chroma: 0, Moments: 0.5}, ColorVariant, ColorVariant},
+		{ColorDistance{Luma: 2, Chroma: 40, Moments: 0}, ColorVariant, ColorVariant},
+		{ColorDistance{Luma: 30, Chroma: 0, Moments: 0}, Different, Different},
+	} {
+		if v := tt.d.Verdict(); v != tt.verdict {
+			t.Errorf("Verdict of %+v is %v, expected %v.", tt.d, v, tt.verdict)
+		}
+		if v := tt.d.VerdictWith(strict); v != tt.strict {
+			t.Errorf("VerdictWith(%+v) of %+v is %v, expected %v.", strict, tt.d, v, tt.strict)
+		}
+	}
+
+	// the Y word is the pHash, and gray images have flat chroma, hue and
+	// saturation
+	img := product(1, 300, 0, colors["gray"])
+	p, _ := PerceptionHash(img)
+	for _, tt := range []struct {
+		space ColorSpace
+		kind  Kind
+		hash  []uint64
+	}{
+		{YCbCr, PHashYCbCr, []uint64{p.GetHash(), 0, 0}},
+		{HSV, PHashHSV, nil},
+	} {
+		h, err := ColorPerceptionHash(img, tt.space)
+		if err != nil {
+			t.Fatalf("ColorPerceptionHash(%v) returned error %v", tt.space, err)
+		}
+		if h.GetKind() != tt.kind || len(h.GetHash()) != 3 {
+			t.Fatalf("ColorPerceptionHash(%v) is %v of %d words, expected %v of 3.", tt.space, h.GetKind(), len(h.GetHash()), tt.kind)
+		}
+		got := h.GetHash()
+		if tt.hash != nil && (got[0] != tt.hash[0] || got[1] != 0 || got[2] != 0) {
+			t.Errorf("ColorPerceptionHash(%v) is %v, expected %x.", tt.space, h, tt.hash)
+		}
+		if tt.hash == nil && (got[0] != 0 || got[1] != 0 || got[2] == 0) {
+			t.Errorf("ColorPerceptionHash(%v) is %v, expected only the V word.", tt.space, h)
+		}
+	}
+
+	// the moments of a flat red, with the luminosity of Rgb2GrayFast
+	red := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := 0; i < len(red.Pix); i += 4 {
+		red.Pix[i], red.Pix[i+3] = 255, 255
+	}
+	m, err := ColorMomentHash(red)
+	if err != nil {
+		t.Fatalf("ColorMomentHash returned error %v", err)
+	}
+	want := [9]float64{0.299, 0, 0, (128 - 0.168736*255) / 255, 0, 0, 255.5 / 255, 0, 0}
+	if got := m.GetHash(); !slices.EqualFunc(got[:], want[:], func(a, b float64) bool { return math.Abs(a-b) < EPSILON }) {
+		t.Errorf("ColorMomentHash of red is %v, expected %v.", got, want)
+	}
+	if !strings.HasPrefix(m.String(), "cm:0.2990,0.0000,") {
+		t.Errorf("ColorMoments String is %q.", m.String())
+	}
+
+	h, _ := ColorPerceptionHash(red, HSV)
+	if _, err := CompareColor(h, h, m, m); err != ErrKindMismatch {
+		t.Errorf("CompareColor of HSV hashes expected error %v but got %v.", ErrKindMismatch, err)
+	}
+	y, _ := ColorPerceptionHash(red, YCbCr)
+	for i, args := range []struct {
+		a, b   *ExtImageHash
+		ma, mb *ColorMoments
+	}{
+		{nil, y, m, m},
+		{y, nil, m, m},
+		{y, y, nil, m},
+		{y, y, m, nil},
+	} {
+		if _, err := CompareColor(args.a, args.b, args.ma, args.mb); err != ErrKindMismatch {
+			t.Errorf("CompareColor with nil argument %d expected error %v but got %v.", i, ErrKindMismatch, err)
+		}
+	}
+	if _, err := ColorMomentHash(nil); err != ErrNilImage {
+		t.Errorf("ColorMomentHash(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+	if _, err := ColorPerceptionHash(image.NewRGBA(image.Rectangle{}), YCbCr); err != ErrEmptyImage {
+		t.Errorf("ColorPerceptionHash of an empty image expected error %v but got %v.", ErrEmptyImage, err)
+	}
+}
+
+func TestHasher(t *testing.T) {
+	img := product(1, 300, 0, color.RGBA{200, 30, 30, 255})
+	p, _ := PerceptionHash(img)
+	pInt, _ := PerceptionHashInt(img)
+	pdq, _, _ := PDQHash(img)
+	bh8, _ := BlockHashQuick(img, 8)
+	wd4, _ := WaveletHashD4(img)
+	hsv, _ := ColorPerceptionHash(img, HSV)
+	rv, _ := RadialVarianceHash(img)
+	cr, _ := CropResistantHash(img)
+
+	for _, tt := range []struct {
+		spec string
+		key  string // HasherSpec, with defaults
+		kind Kind
+		hash Digest // nil if not checked
+	}{
+		{"ahash", "ahash", AHash, nil},
+		{"dhash", "dhash", DHash, nil},
+		{"dhashv", "dhashv", DHashVertical, nil},
+		{"phash", "phash:dct=float", PHash, p},
+		{"phash:dct=int", "phash:dct=int", PHash, pInt},
+		{"pdq", "pdq:minquality=0", PDQ, pdq},
+		{"pdq:minquality=50", "pdq:minquality=50", PDQ, pdq},
+		{"mhash", "mhash", MHash, nil},
+		{"radial", "radial", RadialVariance, rv},
+		{"colormoments", "colormoments", ColorMoment, nil},
+		{"whash", "whash:wavelet=haar", WHash, nil},
+		{"whash:wavelet=d4", "whash:wavelet=d4", WHashD4, wd4},
+		{"blockhash", "blockhash:bits=16,quick=false", BHash, nil},
+		{"blockhash:quick=true,bits=8", "blockhash:bits=8,quick=true", BHash, bh8},
+		{"colorphash", "colorphash:space=ycbcr", PHashYCbCr, nil},
+		{"colorphash:space=hsv", "colorphash:space=hsv", PHashHSV, hsv},
+		{"cropresistant", "cropresistant:limit=0", CropResistant, cr},
+	} {
+		h, err := ParseHasher(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseHasher(%q) returned error %v", tt.spec, err)
+		}
+		if HasherSpec(h) != tt.key {
+			t.Errorf("HasherSpec of %q is %q, expected %q.", tt.spec, HasherSpec(h), tt.key)
+		}
+
+		d, err := h.Hash(img)
+		if err != nil {
+			t.Fatalf("%s: Hash returned error %v", tt.spec, err)
+		}
+		if d.GetKind() != tt.kind || (tt.hash != nil && d.String() != tt.hash.String()) {
+			t.Errorf("%s: Hash is %v, expected %v of kind %v.", tt.spec, d, tt.hash, tt.kind)
+		}
+		if dist, err := h.Distance(d, d); err != nil || dist > EPSILON {
+			t.Errorf("%s: Distance to itself is %v, %v.", tt.spec, dist, err)
+		}
+
+		// a shifted copy stays close and another product does not
+		near, _ := h.Hash(product(1, 300, 2, color.RGBA{200, 30, 30, 255}))
+		far, _ := h.Hash(product(2, 300, 0, color.RGBA{30, 40, 200, 255}))
+		dn, err := h.Distance(d, near)
+		if err != nil {
+			t.Fatalf("%s: Distance returned error %v", tt.spec, err)
+		}
+		if df, _ := h.Distance(d, far); dn >= df {
+			t.Errorf("%s: shifted copy is %v away and another product %v.", tt.spec, dn, df)
+		}
+
+		if _, err := h.Distance(d, NewImageHash(0, Unknown)); err != ErrKindMismatch {
+			t.Errorf("%s: Distance to another kind expected error %v but got %v.", tt.spec, ErrKindMismatch, err)
+		}
+	}
+
+	if names := Hashers(); len(names) != 12 || !slices.IsSorted(names) {
+		t.Errorf("Hashers is %v.", names)
+	}
+
+	for _, spec := range []string{"phash:size=8", "phash:dct=fixed", "blockhash:bits=12", "blockhash:bits=24", "blockhash:bits", "whash:wavelet=db2", "colorphash:space=lab", "cropresistant:limit=-1", "pdq:minquality=101", "pdq:minquality=high"} {
+		_, err := ParseHasher(spec)
+		var perr *ParamError
+		if !errors.Is(err, ErrInvalidParam) || !errors.As(err, &perr) {
+			t.Errorf("ParseHasher(%q) expected error %v but got %v.", spec, ErrInvalidParam, err)
+		}
+	}
+	// a flat image has quality 0
+	h, _ := ParseHasher("pdq:minquality=1")
+	if _, err := h.Hash(image.NewRGBA(image.Rect(0, 0, 64, 64))); !errors.Is(err, ErrLowQuality) {
+		t.Errorf("pdq with minquality=1 of a flat image expected error %v but got %v.", ErrLowQuality, err)
+	}
+	if _, err := ParseHasher("sha256"); !errors.Is(err, ErrUnknownHasher) {
+		t.Errorf("ParseHasher(%q) expected error %v but got %v.", "sha256", ErrUnknownHasher, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register of a name twice did not panic.")
+		}
+	}()
+	Register("phash", nil)
+}
+
+func TestAllDihedralHashes(t *testing.T) {
+	// at 32x32 there is no resizing, so each variant must be the pHash of
+	// the transformed image
+	img := noise(32, 32, 7)
+	hashes, err := AllDihedralHashes(img)
+	if err != nil {
+		t.Fatalf("AllDihedralHashes returned error %v", err)
+	}
+	p, _ := PerceptionHash(img)
+	if *hashes[0] != *p {
+		t.Errorf("Original variant is %v, expected the pHash %v.", hashes[0], p)
+	}
+
+	for i, d := range Dihedrals {
+		h, err := PerceptionHash(dihedral(img, d))
+		if err != nil {
+			t.Fatalf("PerceptionHash returned error %v", err)
+		}
+		if *h != *hashes[i] {
+			t.Errorf("%v variant is %v, expected the pHash of the %v image %v.", d, hashes[i], d, h)
+		}
+
+		dist, match, err := MinDihedralDistance(h, hashes)
+		if err != nil || dist != 0 || match != d {
+			t.Errorf("MinDihedralDistance of the %v image is %d, %v, %v, expected 0, %v.", d, dist, match, err, d)
+		}
+	}
+
+	// resized images are only nearly symmetric, but still match
+	shot := product(3, 300, 0, color.RGBA{40, 160, 50, 255})
+	hashes, err = AllDihedralHashes(shot)
+	if err != nil {
+		t.Fatalf("AllDihedralHashes returned error %v", err)
+	}
+	for _, d := range Dihedrals {
+		h, _ := PerceptionHash(dihedral(shot, d))
+		if dist, match, _ := MinDihedralDistance(h, hashes); dist > 4 || match != d {
+			t.Errorf("MinDihedralDistance of the %v 300x300 image is %d, %v, expected at most 4, %v.", d, dist, match, d)
+		}
+	}
+
+	other, _ := PerceptionHash(product(4, 300, 0, color.RGBA{40, 160, 50, 255}))
+	if dist, _, _ := MinDihedralDistance(other, hashes); dist <= 10 {
+		t.Errorf("MinDihedralDistance of another image is %d, expected above 10.", dist)
+	}
+
+	hashes[5] = NewImageHash(0, AHash)
+	if _, _, err := MinDihedralDistance(other, hashes); err != ErrKindMismatch {
+		t.Errorf("MinDihedralDistance with an AHash expected error %v but got %v.", ErrKindMismatch, err)
+	}
+	if _, _, err := MinDihedralDistance(nil, hashes); err != ErrKindMismatch {
+		t.Errorf("MinDihedralDistance(nil) expected error %v but got %v.", ErrKindMismatch, err)
+	}
+	hashes[5] = nil
+	if _, _, err := MinDihedralDistance(other, hashes); err != ErrKindMismatch {
+		t.Errorf("MinDihedralDistance with a nil variant expected error %v but got %v.", ErrKindMismatch, err)
+	}
+	if _, err := AllDihedralHashes(nil); err != ErrNilImage {
+		t.Errorf("AllDihedralHashes(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+	if _, err := AllDihedralHashes(image.NewRGBA(image.Rectangle{})); err != ErrEmptyImage {
+		t.Errorf("AllDihedralHashes of an empty image expected error %v but got %v.", ErrEmptyImage, err)
+	}
+}
+
+func TestCropResistantHash(t *testing.T) {
+	img := product(2, 400, 0, color.RGBA{200, 30, 30, 255})
+	h, err := CropResistantHash(img)
+	if err != nil {
+		t.Fatalf("CropResistantHash returned error %v", err)
+	}
+	if len(h.GetHash()) < 2 {
+		t.Fatalf("CropResistantHash found %d regions, expected several.", len(h.GetHash()))
+	}
+	if d, err := h.Distance(h); err != nil || d != 0 {
+		t.Errorf("Distance to itself is %v, %v.", d, err)
+	}
+
+	// crops keep some regions whole, and so match, unlike another image
+	for _, r := range []image.Rectangle{
+		image.Rect(40, 40, 400, 400),
+		image.Rect(0, 120, 400, 400),
+		image.Rect(100, 0, 400, 400),
+	} {
+		c, err := CropResistantHash(img.SubImage(r))
+		if err != nil {
+			t.Fatalf("CropResistantHash of %v returned error %v", r, err)
+		}
+		if ok, _ := c.Matches(h); !ok {
+			t.Errorf("Crop %v does not match the image.", r)
+		}
+		if d, _ := c.Distance(h); d >= float64(len(c.GetHash())) {
+			t.Errorf("Distance of crop %v is %v, expected below %d.", r, d, len(c.GetHash()))
+		}
+	}
+	other, _ := CropResistantHash(product(6, 400, 0, color.RGBA{200, 30, 30, 255}))
+	if ok, _ := other.Matches(h); ok {
+		t.Errorf("Another image matches.")
+	}
+	if d, _ := other.Distance(h); d != float64(len(other.GetHash())) {
+		t.Errorf("Distance of another image is %v, expected %d.", d, len(other.GetHash()))
+	}
+
+	parsed, err := ParseMultiHash(h.String())
+	if err != nil || parsed.String() != h.String() {
+		t.Errorf("ParseMultiHash(%q) is %v, %v.", h.String(), parsed, err)
+	}
+	for _, s := range []string{"", "cr:", "p:8f373714acfcf4d0", "cr:8f373714acfcf4d0,07f3", "cr:8f373714acfcf4dg"} {
+		if _, err := ParseMultiHash(s); err != ErrInvalidHash {
+			t.Errorf("ParseMultiHash(%q) expected error %v but got %v.", s, ErrInvalidHash, err)
+		}
+	}
+
+	if l, _ := CropResistantHashLimit(img, 1); len(l.GetHash()) != 1 {
+		t.Errorf("CropResistantHashLimit 1 kept %d regions.", len(l.GetHash()))
+	}
+
+	// a flat image has one region, the whole image
+	flat := image.NewGray(image.Rect(0, 0, 64, 64))
+	f, err := CropResistantHash(flat)
+	if err != nil || len(f.GetHash()) != 1 {
+		t.Errorf("CropResistantHash of a flat image is %v, %v, expected one region.", f, err)
+	}
+	if _, err := CropResistantHash(nil); err != ErrNilImage {
+		t.Errorf("CropResistantHash(nil) expected error %v but got %v.", ErrNilImage, err)
+	}
+	if m, _, err := h.RegionMatches(NewMultiHash(nil), cropCutoff()); err != nil || m != 0 {
+		t.Errorf("RegionMatches of no regions is %d, %v.", m, err)
+	}
+}
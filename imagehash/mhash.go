@@ -0,0 +1,92 @@
+package imagehash
+
+import (
+	"image"
+	"math"
+
+	"go.local/go-image-phash/transforms"
+)
+
+// The Marr-Hildreth hash of pHash's ph_mh_imagehash with its defaults, alpha 2
+// and level 1: the full size luminance, blurred by a Gaussian of sigma 1 and
+// kept in 8 bits, is resized to 512x512 and equalised, correlated with a
+// Laplacian of Gaussian and normalised to [0, 1], summed over 16x16 blocks,
+// and each 3x3 group of blocks, taken every 4 blocks, gives 9 bits against
+// its mean.
+//
+// pHash resizes with cubic interpolation and blurs with CImg's recursive
+// filter, where the grayscale stage resizes linearly and GaussianBlur
+// convolves, so bits of blocks near their group's mean can differ. It also
+// takes the luma of YCbCr, which is an affine function of this one, so the
+// equalisation removes the difference.
+const (
+	mhSize   = 512 // side of the image
+	mhBlock  = 16  // side of the blocks summed
+	mhBlocks = 31  // blocks along each side
+	mhAlpha  = 2.0
+	mhLevel  = 1.0
+	mhSigma  = 1.0 // of the blur before resizing
+)
+
+// MarrHildrethHash returns the 576 bit Marr-Hildreth hash of img, which tells
+// line art and logos apart better than the DCT hash. Compare hashes with
+// NormalizedDistance for pHash's ph_hammingdistance2.
+func MarrHildrethHash(img image.Image) (*ExtImageHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	// pHash blurs an 8 bit image in place
+	w, h := b.Dx(), b.Dy()
+	blurred := image.NewGray(image.Rect(0, 0, w, h))
+	for i, v := range transforms.GaussianBlur(grayscale(img, w, h), w, h, mhSigma) {
+		blurred.Pix[i] = uint8(min(max(math.Round(v), 0), 255))
+	}
+
+	pixels := grayscale(blurred, mhSize, mhSize)
+	transforms.Equalize(pixels, 256)
+
+	kernel, size := transforms.LoGKernel(mhAlpha, mhLevel)
+	resp := transforms.Correlate(pixels, mhSize, mhSize, kernel, size)
+	transforms.Normalize(resp, 0, 1)
+
+	// pHash keeps the block sums in a float image and takes the mean of a
+	// group in double, so the mean of a flat group is its blocks' sum and
+	// gives 0 bits
+	var sums [mhBlocks * mhBlocks]float64
+	for y := 0; y < mhBlocks*mhBlock; y++ {
+		for x := 0; x < mhBlocks*mhBlock; x++ {
+			sums[(y/mhBlock)*mhBlocks+x/mhBlock] += resp[y*mhSize+x]
+		}
+	}
+	var blocks [mhBlocks * mhBlocks]float32
+	for i, v := range sums {
+		blocks[i] = float32(v)
+	}
+
+	hash := make([]uint64, 9)
+	bit := 0
+	for by := 0; by < mhBlocks-2; by += 4 {
+		for bx := 0; bx < mhBlocks-2; bx += 4 {
+			var group [9]float32
+			var total float64
+			for i := range group {
+				group[i] = blocks[(by+i/3)*mhBlocks+bx+i%3]
+				total += float64(group[i])
+			}
+			mean := float32(total / 9)
+
+			for _, v := range group {
+				if v > mean {
+					hash[bit/64] |= 1 << (63 - bit%64)
+				}
+				bit++
+			}
+		}
+	}
+	return NewExtImageHash(hash, MHash), nil
+}
@@ -0,0 +1,263 @@
+package imagehash
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"slices"
+
+	"go.local/go-image-phash/dct"
+)
+
+// PDQ, after Facebook's reference implementation in ThreatExchange: the
+// luminance is blurred with two passes of a box filter along each axis, a
+// Jarosz filter approximating a tent, and decimated to 64x64. Its DCT-II,
+// without the DC row and column, gives a 16x16 block whose 256 signs against
+// the median are the hash.
+//
+// The hashes must match the reference's to the bit, and coefficients within
+// rounding of the median are common in smooth images, so every stage is
+// computed as the reference does, in float32 and in the same order, with
+// dct.PartialDCT2DF32's matrix product rather than the fast transforms. The
+// products are rounded with float32() so they are never fused into FMAs.
+// Decoders differ in the colour conversion of JPEG, so only lossless images
+// are certain to give the reference's bits.
+const (
+	// pdqSize is the side of the buffer PDQ decimates to.
+	pdqSize = 64
+	// pdqCorner is the side of the DCT block PDQ keeps.
+	pdqCorner = 16
+	// pdqJaroszReps is the number of box filter passes along each axis.
+	pdqJaroszReps = 2
+	// pdqMinSide is the smallest side the reference hashes; smaller images
+	// hash to 0 with quality 0.
+	pdqMinSide = 5
+)
+
+// ErrLowQuality is returned by the "pdq" Hasher for an image whose quality
+// is below its minquality.
+var ErrLowQuality = errors.New("imagehash: PDQ quality is below the minimum")
+
+// Luminance weights of the reference, float constants in C++.
+var (
+	pdqLumaR = float32(float64(0.299))
+	pdqLumaG = float32(float64(0.587))
+	pdqLumaB = float32(float64(0.114))
+)
+
+// PDQHash returns the 256 bit PDQ hash of img and its quality, 0 to 100, a
+// measure of the gradients in the image below which, at about 50, the
+// reference advises against trusting the hash. The String form is the
+// reference's hex. As the reference, the luminance is taken from the
+// non-premultiplied colours, ignoring alpha.
+func PDQHash(img image.Image) (*ExtImageHash, int, error) {
+	coef, quality, err := pdqDCT(img)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pdqBits(&coef), quality, nil
+}
+
+// PDQHashDihedral returns the PDQ hashes of the 8 Dihedrals of img, in their
+// order, and its quality. They are derived from one DCT by sign changes and
+// transposes, as the reference does, rather than by transforming the image.
+func PDQHashDihedral(img image.Image) ([8]*ExtImageHash, int, error) {
+	var hashes [8]*ExtImageHash
+	coef, quality, err := pdqDCT(img)
+	if err != nil {
+		return hashes, 0, err
+	}
+
+	for i, d := range Dihedrals {
+		out := pdqDihedral(&coef, d)
+		hashes[i] = pdqBits(&out)
+	}
+	return hashes, quality, nil
+}
+
+// pdqDCT returns the 16x16 DCT block PDQ hashes and the quality of img.
+func pdqDCT(img image.Image) (coef [pdqCorner * pdqCorner]float64, quality int, err error) {
+	if img == nil {
+		return coef, 0, ErrNilImage
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return coef, 0, ErrEmptyImage
+	}
+
+	rows, cols := b.Dy(), b.Dx()
+	if rows < pdqMinSide || cols < pdqMinSide {
+		return coef, 0, nil
+	}
+	luma := pdqLuma(img)
+
+	jaroszFilter(luma, rows, cols, jaroszWindowSize(cols), jaroszWindowSize(rows))
+	buf := decimate(luma, rows, cols)
+	quality = pdqQuality(buf)
+
+	// the reference's dct64To16, frequencies 1 to 16
+	block, err := dct.PartialDCT2DF32(buf, pdqSize, 1, pdqCorner)
+	if err != nil {
+		return coef, 0, err
+	}
+	for i, c := range block {
+		coef[i] = float64(c)
+	}
+	return coef, quality, nil
+}
+
+// pdqLuma returns the luminance of img row by row, from its 8 bit
+// non-premultiplied colours, with JPEG's 8 bit YCbCr conversion.
+func pdqLuma(img image.Image) []float32 {
+	rgb := func(x, y int) (r, g, b uint8) {
+		c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+		return c.R, c.G, c.B
+	}
+	switch img := img.(type) {
+	case *image.NRGBA:
+		rgb = func(x, y int) (r, g, b uint8) {
+			c := img.NRGBAAt(x, y)
+			return c.R, c.G, c.B
+		}
+	case *image.YCbCr:
+		rgb = func(x, y int) (r, g, b uint8) {
+			c := img.YCbCrAt(x, y)
+			return color.YCbCrToRGB(c.Y, c.Cb, c.Cr)
+		}
+	}
+
+	bounds := img.Bounds()
+	luma := make([]float32, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b := rgb(x, y)
+			v := float32(pdqLumaR*float32(r)) + float32(pdqLumaG*float32(g))
+			luma = append(luma, v+float32(pdqLumaB*float32(b)))
+		}
+	}
+	return luma
+}
+
+// pdqBits sets bit k of the 256 bit hash, counting from the least
+// significant, if coefficient k exceeds the median, the 128th smallest.
+func pdqBits(coef *[pdqCorner * pdqCorner]float64) *ExtImageHash {
+	sorted := slices.Clone(coef[:])
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2-1]
+
+	hash := make([]uint64, len(coef)/64)
+	for k, c := range coef {
+		if c > median {
+			hash[len(hash)-1-k/64] |= 1 << (k % 64)
+		}
+	}
+	return NewExtImageHash(hash, PDQ)
+}
+
+// pdqDihedral returns the DCT block of the image transformed by d. The
+// block leaves out the DC terms, so its row u is frequency u+1.
+func pdqDihedral(coef *[pdqCorner * pdqCorner]float64, d Dihedral) (out [pdqCorner * pdqCorner]float64) {
+	copy(out[:], dihedralDCT(coef[:], pdqCorner, 1, d))
+	return out
+}
+
+// jaroszWindowSize is the box filter window for decimating a side of n
+// pixels to pdqSize, half the decimation step, rounded up.
+func jaroszWindowSize(n int) int {
+	return (n + 2*pdqSize - 1) / (2 * pdqSize)
+}
+
+// jaroszFilter blurs the rows x cols buf in place with pdqJaroszReps box
+// filters along the rows, of rowWindow, and the columns, of colWindow.
+func jaroszFilter(buf []float32, rows, cols, rowWindow, colWindow int) {
+	temp := make([]float32, len(buf))
+	for rep := 0; rep < pdqJaroszReps; rep++ {
+		for y := 0; y < rows; y++ {
+			box1D(buf[y*cols:], temp[y*cols:], cols, 1, rowWindow)
+		}
+		for x := 0; x < cols; x++ {
+			box1D(temp[x:], buf[x:], rows, cols, colWindow)
+		}
+	}
+}
+
+// box1D writes the mean of the window of each of the n elements of in, stride
+// apart, to out. The window is centred, a pixel to the right for even
+// windows, and shrinks at the ends, as the reference's box1DFloat.
+func box1D(in, out []float32, n, stride, window int) {
+	half := (window + 2) / 2
+	var sum float32
+	size := 0
+	li, ri, oi := 0, 0, 0
+
+	// accumulate the first sum without writes
+	for i := 0; i < half-1; i++ {
+		sum += in[ri]
+		size++
+		ri += stride
+	}
+	// the window grows
+	for i := 0; i < window-half+1; i++ {
+		sum += in[ri]
+		size++
+		out[oi] = sum / float32(size)
+		ri += stride
+		oi += stride
+	}
+	// the full window slides
+	for i := 0; i < n-window; i++ {
+		sum += in[ri]
+		sum -= in[li]
+		out[oi] = sum / float32(size)
+		li += stride
+		ri += stride
+		oi += stride
+	}
+	// the window shrinks
+	for i := 0; i < half-1; i++ {
+		sum -= in[li]
+		size--
+		out[oi] = sum / float32(size)
+		li += stride
+		oi += stride
+	}
+}
+
+// decimate samples the rows x cols buf at the centres of a pdqSize grid.
+func decimate(buf []float32, rows, cols int) []float32 {
+	out := make([]float32, pdqSize*pdqSize)
+	for i := 0; i < pdqSize; i++ {
+		y := int((float64(i) + 0.5) * float64(rows) / pdqSize)
+		for j := 0; j < pdqSize; j++ {
+			x := int((float64(j) + 0.5) * float64(cols) / pdqSize)
+			out[i*pdqSize+j] = buf[y*cols+x]
+		}
+	}
+	return out
+}
+
+// pdqQuality is the reference's pdqImageDomainQualityMetric: the sum of the
+// absolute differences between neighbours of the decimated image, as whole
+// percentages of the full range, scaled heuristically and capped at 100.
+func pdqQuality(buf []float32) int {
+	sum := 0
+	for i := 0; i < pdqSize; i++ {
+		for j := 0; j < pdqSize; j++ {
+			u := buf[i*pdqSize+j]
+			if i+1 < pdqSize {
+				sum += abs(int((u - buf[(i+1)*pdqSize+j]) * 100 / 255))
+			}
+			if j+1 < pdqSize {
+				sum += abs(int((u - buf[i*pdqSize+j+1]) * 100 / 255))
+			}
+		}
+	}
+	return min(sum/90, 100)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
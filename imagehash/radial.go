@@ -0,0 +1,189 @@
+package imagehash
+
+import (
+	"encoding/hex"
+	"image"
+	"math"
+	"strings"
+
+	"go.local/go-image-phash/dct"
+	"go.local/go-image-phash/radon"
+	"go.local/go-image-phash/transforms"
+)
+
+// The radial variance digest of pHash's ph_image_digest with its defaults:
+// the luminance, blurred with a Gaussian of sigma 1, is projected along 180
+// lines through its centre, and the variances of the projections,
+// standardised, are reduced to their 40 lowest orthonormal DCT-II
+// coefficients scaled onto 0..255.
+//
+// pHash takes the luma of YCbCr, an affine function of this one, which the
+// standardisation removes. Its gamma step discards its result, so there is
+// none here.
+const (
+	radialAngles = 180
+	radialCoeffs = 40
+	radialSigma  = 1.0
+
+	// radialTolerance is the spread of the variances, in squared gray
+	// levels, below which they are taken to be equal, so the rounding of a
+	// flat image is not standardised into a digest.
+	radialTolerance = 1e-9
+
+	// RadialThreshold is pHash's default peak cross-correlation above which
+	// two digests are of the same image.
+	RadialThreshold = 0.90
+)
+
+// RadialDigest is a radial variance digest, compared by its peak
+// cross-correlation rather than a Hamming distance.
+type RadialDigest struct {
+	coeffs []uint8
+	kind   Kind
+}
+
+// NewRadialDigest returns a RadialDigest holding coeffs.
+func NewRadialDigest(coeffs []uint8) *RadialDigest {
+	return &RadialDigest{coeffs: coeffs, kind: RadialVariance}
+}
+
+// GetHash returns the coefficients of d.
+func (d *RadialDigest) GetHash() []uint8 {
+	return d.coeffs
+}
+
+// GetKind returns the algorithm d was computed with.
+func (d *RadialDigest) GetKind() Kind {
+	return d.kind
+}
+
+// RadialVarianceHash returns the radial variance digest of img, which
+// tolerates rotation by a few degrees. Rotating the image shifts the
+// variances circularly, which their DCT does not follow, so larger angles
+// are not matched. It works on the full size image.
+func RadialVarianceHash(img image.Image) (*RadialDigest, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	w, h := b.Dx(), b.Dy()
+	pixels := transforms.GaussianBlur(grayscale(img, w, h), w, h, radialSigma)
+	projs, err := radon.Project(pixels, w, h, radialAngles)
+	if err != nil {
+		return nil, err
+	}
+
+	features := radialFeatures(projs)
+	coef := dct.DCT_1D_Norm(features, len(features), dct.NormOrtho)[:radialCoeffs]
+
+	var lo, hi float64
+	for _, c := range coef {
+		lo, hi = min(lo, c), max(hi, c)
+	}
+	coeffs := make([]uint8, radialCoeffs)
+	if hi > lo {
+		for i, c := range coef {
+			coeffs[i] = uint8(255 * (c - lo) / (hi - lo))
+		}
+	}
+	return NewRadialDigest(coeffs), nil
+}
+
+// radialFeatures returns the variance of the samples on each projection,
+// standardised, as pHash's ph_feature_vector. The variances of a flat image
+// are all equal and stay 0.
+func radialFeatures(projs *radon.Projections) []float64 {
+	features := make([]float64, projs.N)
+	var sum, sumSqd float64
+	for k := range features {
+		n := float64(projs.Count[k])
+		if n == 0 {
+			continue
+		}
+		var lineSum, lineSumSqd float64
+		for _, v := range projs.Line(k) {
+			lineSum += v
+			lineSumSqd += v * v
+		}
+		features[k] = lineSumSqd/n - lineSum*lineSum/(n*n)
+		sum += features[k]
+		sumSqd += features[k] * features[k]
+	}
+
+	n := float64(len(features))
+	mean := sum / n
+	sd := math.Sqrt(sumSqd/n - mean*mean)
+	if !(sd > radialTolerance) {
+		return make([]float64, len(features))
+	}
+	for i := range features {
+		features[i] = (features[i] - mean) / sd
+	}
+	return features
+}
+
+// CrossCorrelation returns the peak, over all circular shifts, of the
+// normalised cross-correlation between d and other, as pHash's
+// ph_crosscorr. Digests of the same image give about 1; above
+// RadialThreshold they are taken as a match. A flat digest correlates with
+// nothing. A nil digest gives ErrKindMismatch.
+func (d *RadialDigest) CrossCorrelation(other *RadialDigest) (float64, error) {
+	if d == nil || other == nil || d.kind != other.kind {
+		return -1, ErrKindMismatch
+	}
+	if len(d.coeffs) != len(other.coeffs) {
+		return -1, ErrLengthMismatch
+	}
+
+	n := len(d.coeffs)
+	var sumx, sumy float64
+	for i := 0; i < n; i++ {
+		sumx += float64(d.coeffs[i])
+		sumy += float64(other.coeffs[i])
+	}
+	meanx, meany := sumx/float64(n), sumy/float64(n)
+
+	var peak float64
+	for shift := 0; shift < n; shift++ {
+		var num, denx, deny float64
+		for i := 0; i < n; i++ {
+			x := float64(d.coeffs[i]) - meanx
+			y := float64(other.coeffs[(n+i-shift)%n]) - meany
+			num += x * y
+			denx += x * x
+			deny += y * y
+		}
+		if denx == 0 || deny == 0 {
+			continue
+		}
+		peak = max(peak, num/math.Sqrt(denx*deny))
+	}
+	return peak, nil
+}
+
+// String returns d as its kind tag and its coefficients in hex, "rv:" and
+// 80 digits.
+func (d *RadialDigest) String() string {
+	prefix, ok := kindPrefix[d.kind]
+	if !ok {
+		prefix = "?"
+	}
+	return prefix + ":" + hex.EncodeToString(d.coeffs)
+}
+
+// ParseRadialDigest parses the String form of a RadialDigest.
+func ParseRadialDigest(s string) (*RadialDigest, error) {
+	digits, ok := strings.CutPrefix(s, kindPrefix[RadialVariance]+":")
+	if !ok || len(digits) == 0 {
+		return nil, ErrInvalidHash
+	}
+	coeffs, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, ErrInvalidHash
+	}
+	return NewRadialDigest(coeffs), nil
+}
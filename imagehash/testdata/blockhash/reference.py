@@ -0,0 +1,239 @@
+#!/usr/bin/env python3
+"""Writes the blockhash fixtures of TestBlockHashReference and their hashes.
+
+The hashing is a line by line transcription of blockhash.py in
+blockhash-python (commonsmachinery), whose blockhash and blockhash_even are
+the precise and quick methods, the latter behind its --quick flag. The
+JavaScript blockhash-js computes the same bits. It takes the pixels as PIL
+gives those of an RGB or RGBA image, and needs only the standard library.
+
+The images are lossless PNGs drawn here, so no decoder differences enter.
+Each line of hashes.csv is filename,precise 8,quick 8,precise 16,quick 16:
+the image's precise and quick hashes with 8 bits and with 16 bits. Hashes of
+the reference's own sample images can be added as further lines with their
+files.
+
+Run it from this directory: python3 reference.py
+"""
+
+import math
+import struct
+import zlib
+
+
+def median(data):
+    data = sorted(data)
+    length = len(data)
+    if length % 2 == 0:
+        return (data[length // 2 - 1] + data[length // 2]) / 2.0
+    return data[length // 2]
+
+
+def total_value_rgba(data, width, x, y):
+    r, g, b, a = data[y * width + x]
+    if a == 0:
+        return 765
+    return r + g + b
+
+
+def total_value_rgb(data, width, x, y):
+    r, g, b = data[y * width + x][:3]
+    return r + g + b
+
+
+def translate_blocks_to_bits(blocks, pixels_per_block):
+    half_block_value = pixels_per_block * 256 * 3 / 2
+
+    # compare medians across four horizontal bands
+    bandsize = len(blocks) // 4
+    for i in range(4):
+        m = median(blocks[i * bandsize : (i + 1) * bandsize])
+        for j in range(i * bandsize, (i + 1) * bandsize):
+            v = blocks[j]
+            blocks[j] = int(v > m or (abs(v - m) < 1 and m > half_block_value))
+
+
+def bits_to_hexhash(bits):
+    return "{0:0={width}x}".format(int("".join(str(x) for x in bits), 2), width=len(bits) // 4)
+
+
+def blockhash_even(data, width, height, alpha, bits):
+    total_value = total_value_rgba if alpha else total_value_rgb
+    blocksize_x = width // bits
+    blocksize_y = height // bits
+
+    result = []
+    for y in range(bits):
+        for x in range(bits):
+            value = 0
+            for iy in range(blocksize_y):
+                for ix in range(blocksize_x):
+                    cx = x * blocksize_x + ix
+                    cy = y * blocksize_y + iy
+                    value += total_value(data, width, cx, cy)
+            result.append(value)
+
+    translate_blocks_to_bits(result, blocksize_x * blocksize_y)
+    return bits_to_hexhash(result)
+
+
+def blockhash(data, width, height, alpha, bits):
+    total_value = total_value_rgba if alpha else total_value_rgb
+    even_x = width % bits == 0
+    even_y = height % bits == 0
+    if even_x and even_y:
+        return blockhash_even(data, width, height, alpha, bits)
+
+    blocks = [[0 for col in range(bits)] for row in range(bits)]
+    block_width = float(width) / bits
+    block_height = float(height) / bits
+
+    for y in range(height):
+        if even_y:
+            block_top = block_bottom = int(y // block_height)
+            weight_top, weight_bottom = 1, 0
+        else:
+            y_frac, y_int = math.modf((y + 1) % block_height)
+            weight_top = 1 - y_frac
+            weight_bottom = y_frac
+            # y_int is 0 on the bottom border and on block boundaries
+            if y_int > 0 or (y + 1) == height:
+                block_top = block_bottom = int(y // block_height)
+            else:
+                block_top = int(y // block_height)
+                block_bottom = int(-(-y // block_height))
+
+        for x in range(width):
+            value = total_value(data, width, x, y)
+            if even_x:
+                block_left = block_right = int(x // block_width)
+                weight_left, weight_right = 1, 0
+            else:
+                x_frac, x_int = math.modf((x + 1) % block_width)
+                weight_left = 1 - x_frac
+                weight_right = x_frac
+                if x_int > 0 or (x + 1) == width:
+                    block_left = block_right = int(x // block_width)
+                else:
+                    block_left = int(x // block_width)
+                    block_right = int(-(-x // block_width))
+
+            blocks[block_top][block_left] += value * weight_top * weight_left
+            blocks[block_top][block_right] += value * weight_top * weight_right
+            blocks[block_bottom][block_left] += value * weight_bottom * weight_left
+            blocks[block_bottom][block_right] += value * weight_bottom * weight_right
+
+    result = [blocks[row][col] for row in range(bits) for col in range(bits)]
+    translate_blocks_to_bits(result, block_width * block_height)
+    return bits_to_hexhash(result)
+
+
+# fixtures
+
+def write_png(path, width, height, pixels, alpha=False):
+    channels = 4 if alpha else 3
+    raw = bytearray()
+    for y in range(height):
+        raw.append(0)
+        for x in range(width):
+            raw.extend(pixels[y * width + x][:channels])
+
+    def chunk(kind, data):
+        c = struct.pack(">I", len(data)) + kind + data
+        return c + struct.pack(">I", zlib.crc32(kind + data) & 0xFFFFFFFF)
+
+    header = struct.pack(">IIBBBBB", width, height, 8, 6 if alpha else 2, 0, 0, 0)
+    with open(path, "wb") as f:
+        f.write(b"\x89PNG\r\n\x1a\n")
+        f.write(chunk(b"IHDR", header))
+        f.write(chunk(b"IDAT", zlib.compress(bytes(raw), 9)))
+        f.write(chunk(b"IEND", b""))
+
+
+def clamp(v):
+    return max(0, min(255, int(v)))
+
+
+def lcg(seed):
+    state = seed
+    while True:
+        state = (state * 1103515245 + 12345) & 0x7FFFFFFF
+        yield state >> 16
+
+
+def gradient(w, h):
+    return [(x * 255 // (w - 1), y * 255 // (h - 1), (x + y) * 127 // (w + h - 2)) for y in range(h) for x in range(w)]
+
+
+def shapes(w, h):
+    out = []
+    for y in range(h):
+        for x in range(w):
+            c = (230, 225, 210)
+            if (x - w * 0.3) ** 2 + (y - h * 0.4) ** 2 < (h * 0.3) ** 2:
+                c = (200, 30, 30)
+            if w * 0.55 < x < w * 0.9 and h * 0.2 < y < h * 0.7:
+                c = (30, 60, 180)
+            if abs((x - w * 0.5) - (y - h * 0.8) * 2) < 4:
+                c = (20, 20, 20)
+            out.append(c)
+    return out
+
+
+def noise(w, h, seed):
+    r = lcg(seed)
+    return [(next(r) & 255, next(r) & 255, next(r) & 255) for _ in range(w * h)]
+
+
+def dark(w, h):
+    # mostly black, so the medians of the bands are 0
+    return [(255, 255, 255) if (x - w // 2) ** 2 + (y - h // 2) ** 2 < 36 else (0, 0, 0) for y in range(h) for x in range(w)]
+
+
+def light(w, h):
+    # mostly white, so blocks equal to the medians are set
+    return [(0, 0, 0) if x == y else (255, 255, 255) for y in range(h) for x in range(w)]
+
+
+def cutout(w, h):
+    # fully transparent outside an ellipse, whatever the colour there
+    out = []
+    for y in range(h):
+        for x in range(w):
+            inside = ((x - w / 2) / (w * 0.4)) ** 2 + ((y - h / 2) / (h * 0.45)) ** 2 < 1
+            a = 255 if inside else 0
+            if inside and x > w // 2:
+                a = 128
+            out.append((x * 3 & 255, 255 - y * 3 & 255, (x ^ y) & 255, a))
+    return out
+
+
+FIXTURES = [
+    ("gradient-64x64.png", 64, 64, lambda: gradient(64, 64), False),
+    ("gradient-48x40.png", 48, 40, lambda: gradient(48, 40), False),
+    ("shapes-257x129.png", 257, 129, lambda: shapes(257, 129), False),
+    ("shapes-150x100.png", 150, 100, lambda: shapes(150, 100), False),
+    ("noise-37x29.png", 37, 29, lambda: noise(37, 29, 11), False),
+    ("noise-20x20.png", 20, 20, lambda: noise(20, 20, 3), False),
+    ("dark-33x33.png", 33, 33, lambda: dark(33, 33), False),
+    ("light-40x40.png", 40, 40, lambda: light(40, 40), False),
+    ("cutout-90x70.png", 90, 70, lambda: cutout(90, 70), True),
+]
+
+
+def main():
+    lines = []
+    for name, w, h, draw, alpha in FIXTURES:
+        pixels = draw()
+        write_png(name, w, h, pixels, alpha)
+        hashes = []
+        for bits in (8, 16):
+            hashes.append(blockhash(pixels, w, h, alpha, bits))
+            hashes.append(blockhash_even(pixels, w, h, alpha, bits))
+        lines.append(",".join([name] + hashes))
+    with open("hashes.csv", "w") as f:
+        f.write("\n".join(lines) + "\n")
+
+
+if __name__ == "__main__":
+    main()
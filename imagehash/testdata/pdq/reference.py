@@ -0,0 +1,323 @@
+#!/usr/bin/env python3
+"""Writes the PDQ fixtures of TestPDQReference and their expected hashes.
+
+The hashing is a line by line transcription of pdqhashing.cpp in Facebook's
+ThreatExchange (pdq/cpp/hashing), in the same order of float32 operations:
+each sum, product and quotient is rounded to float32 as the C++ does without
+FMA contraction. It needs only the standard library.
+
+The images are lossless PNGs drawn here, so no decoder differences enter.
+hashes.csv is in the reference pdq-photo-hasher's output format,
+hash,quality,filename, and dihedral.csv lists the eight dihedral hashes of
+each image in the reference's order. Lines for the reference's own sample
+images, with their published hashes, can be appended to hashes.csv as they
+are.
+
+Run it from this directory: python3 reference.py
+"""
+
+import math
+import struct
+import zlib
+
+_F32 = struct.Struct("<f")
+
+
+def f32(x):
+    """Rounds x to float32, as a C++ float assignment does."""
+    return _F32.unpack(_F32.pack(x))[0]
+
+
+LUMA_FROM_R_COEFF = f32(0.299)
+LUMA_FROM_G_COEFF = f32(0.587)
+LUMA_FROM_B_COEFF = f32(0.114)
+
+MIN_HASHABLE_DIM = 5
+DOWNSAMPLE_DIMS = 64
+PDQ_NUM_JAROSZ_XY_PASSES = 2
+
+
+def fill_float_luma(rgb, num_rows, num_cols):
+    luma = [0.0] * (num_rows * num_cols)
+    for i in range(num_rows * num_cols):
+        r, g, b = rgb[i]
+        luma[i] = f32(f32(f32(LUMA_FROM_R_COEFF * r) + f32(LUMA_FROM_G_COEFF * g)) + f32(LUMA_FROM_B_COEFF * b))
+    return luma
+
+
+def compute_jarosz_filter_window_size(old_dimension, new_dimension):
+    return (old_dimension + 2 * new_dimension - 1) // (2 * new_dimension)
+
+
+def box1d_float(invec, inoff, outvec, outoff, vector_length, stride, full_window_size):
+    half_window_size = (full_window_size + 2) // 2
+    phase_1_nreps = half_window_size - 1
+    phase_2_nreps = full_window_size - half_window_size + 1
+    phase_3_nreps = vector_length - full_window_size
+    phase_4_nreps = half_window_size - 1
+    li = ri = oi = 0
+    total = 0.0
+    current_window_size = 0
+
+    for _ in range(phase_1_nreps):
+        total = f32(total + invec[inoff + ri])
+        current_window_size += 1
+        ri += stride
+    for _ in range(phase_2_nreps):
+        total = f32(total + invec[inoff + ri])
+        current_window_size += 1
+        outvec[outoff + oi] = f32(total / current_window_size)
+        ri += stride
+        oi += stride
+    for _ in range(phase_3_nreps):
+        total = f32(total + invec[inoff + ri])
+        total = f32(total - invec[inoff + li])
+        outvec[outoff + oi] = f32(total / current_window_size)
+        li += stride
+        ri += stride
+        oi += stride
+    for _ in range(phase_4_nreps):
+        total = f32(total - invec[inoff + li])
+        current_window_size -= 1
+        outvec[outoff + oi] = f32(total / current_window_size)
+        li += stride
+        oi += stride
+
+
+def jarosz_filter_float(buffer1, buffer2, num_rows, num_cols, window_along_rows, window_along_cols, nreps):
+    for _ in range(nreps):
+        for i in range(num_rows):
+            box1d_float(buffer1, i * num_cols, buffer2, i * num_cols, num_cols, 1, window_along_rows)
+        for j in range(num_cols):
+            box1d_float(buffer2, j, buffer1, j, num_rows, num_cols, window_along_cols)
+
+
+def decimate_float(inbuf, in_rows, in_cols, out_rows, out_cols):
+    out = [0.0] * (out_rows * out_cols)
+    for i in range(out_rows):
+        ini = int(((i + 0.5) * in_rows) / out_rows)
+        for j in range(out_cols):
+            inj = int(((j + 0.5) * in_cols) / out_cols)
+            out[i * out_cols + j] = inbuf[ini * in_cols + inj]
+    return out
+
+
+def quality_metric(buf):
+    gradient_sum = 0
+    for i in range(63):
+        for j in range(64):
+            u, v = buf[i * 64 + j], buf[(i + 1) * 64 + j]
+            d = int(f32(f32(f32(u - v) * 100) / 255))
+            gradient_sum += abs(d)
+    for i in range(64):
+        for j in range(63):
+            u, v = buf[i * 64 + j], buf[i * 64 + j + 1]
+            d = int(f32(f32(f32(u - v) * 100) / 255))
+            gradient_sum += abs(d)
+    return min(gradient_sum // 90, 100)
+
+
+def dct_matrix_64():
+    scale = f32(math.sqrt(2.0 / 64.0))
+    return [[f32(scale * math.cos((math.pi / 2 / 64.0) * (i + 1) * (2 * j + 1))) for j in range(64)] for i in range(16)]
+
+
+def dct64_to_16(a):
+    d = dct_matrix_64()
+    t = [[0.0] * 64 for _ in range(16)]
+    for i in range(16):
+        for j in range(64):
+            s = 0.0
+            for k in range(64):
+                s = f32(s + f32(d[i][k] * a[k * 64 + j]))
+            t[i][j] = s
+    b = [[0.0] * 16 for _ in range(16)]
+    for i in range(16):
+        for j in range(16):
+            s = 0.0
+            for k in range(64):
+                s = f32(s + f32(t[i][k] * d[j][k]))
+            b[i][j] = s
+    return b
+
+
+def torben(values):
+    # the (n+1)/2-th smallest value, as the reference's Torben median
+    return sorted(values)[(len(values) + 1) // 2 - 1]
+
+
+def to_hex(b):
+    median = torben([v for row in b for v in row])
+    words = [0] * 16
+    for i in range(16):
+        for j in range(16):
+            if b[i][j] > median:
+                k = i * 16 + j
+                words[k >> 4] |= 1 << (k & 15)
+    return "".join("%04x" % w for w in reversed(words))
+
+
+def dihedrals(a):
+    """The reference's dct16OriginalTo* transforms, in its output order."""
+    out = []
+    for name in ("orig", "rot90", "rot180", "rot270", "flipx", "flipy", "flipplus1", "flipminus1"):
+        b = [[0.0] * 16 for _ in range(16)]
+        for i in range(16):
+            for j in range(16):
+                v = a[i][j]
+                if name == "orig":
+                    b[i][j] = v
+                elif name == "rot90":
+                    b[j][i] = v if j & 1 else -v
+                elif name == "rot180":
+                    b[i][j] = -v if (i + j) & 1 else v
+                elif name == "rot270":
+                    b[j][i] = v if i & 1 else -v
+                elif name == "flipx":
+                    b[i][j] = v if i & 1 else -v
+                elif name == "flipy":
+                    b[i][j] = v if j & 1 else -v
+                elif name == "flipplus1":
+                    b[j][i] = v
+                else:
+                    b[j][i] = -v if (i + j) & 1 else v
+        out.append(b)
+    return out
+
+
+def pdq_hashes(rgb, num_rows, num_cols):
+    """Returns the eight dihedral hashes and the quality, as
+    pdqHash256esFromFloatLuma."""
+    if num_rows < MIN_HASHABLE_DIM or num_cols < MIN_HASHABLE_DIM:
+        return ["0" * 64] * 8, 0
+    buffer1 = fill_float_luma(rgb, num_rows, num_cols)
+    buffer2 = [0.0] * (num_rows * num_cols)
+    jarosz_filter_float(
+        buffer1, buffer2, num_rows, num_cols,
+        compute_jarosz_filter_window_size(num_cols, DOWNSAMPLE_DIMS),
+        compute_jarosz_filter_window_size(num_rows, DOWNSAMPLE_DIMS),
+        PDQ_NUM_JAROSZ_XY_PASSES)
+    buf64 = decimate_float(buffer1, num_rows, num_cols, 64, 64)
+    quality = quality_metric(buf64)
+    return [to_hex(b) for b in dihedrals(dct64_to_16(buf64))], quality
+
+
+# fixtures
+
+def write_png(path, width, height, pixels, alpha=False):
+    channels = 4 if alpha else 3
+    raw = bytearray()
+    for y in range(height):
+        raw.append(0)
+        for x in range(width):
+            raw.extend(pixels[y * width + x][:channels])
+
+    def chunk(kind, data):
+        c = struct.pack(">I", len(data)) + kind + data
+        return c + struct.pack(">I", zlib.crc32(kind + data) & 0xFFFFFFFF)
+
+    header = struct.pack(">IIBBBBB", width, height, 8, 6 if alpha else 2, 0, 0, 0)
+    with open(path, "wb") as f:
+        f.write(b"\x89PNG\r\n\x1a\n")
+        f.write(chunk(b"IHDR", header))
+        f.write(chunk(b"IDAT", zlib.compress(bytes(raw), 9)))
+        f.write(chunk(b"IEND", b""))
+
+
+def clamp(v):
+    return max(0, min(255, int(v)))
+
+
+def lcg(seed):
+    state = seed
+    while True:
+        state = (state * 1103515245 + 12345) & 0x7FFFFFFF
+        yield state >> 16
+
+
+def gradient(w, h):
+    return [(x * 255 // (w - 1), y * 255 // (h - 1), (x + y) * 127 // (w + h - 2)) for y in range(h) for x in range(w)]
+
+
+def waves(w, h):
+    out = []
+    for y in range(h):
+        for x in range(w):
+            v = 128 + 60 * math.sin(x / 23) + 60 * math.cos((x + 2 * y) / 37)
+            out.append((clamp(v), clamp(255 - v), clamp(v / 2)))
+    return out
+
+
+def shapes(w, h):
+    out = []
+    for y in range(h):
+        for x in range(w):
+            c = (230, 225, 210)
+            if (x - w * 0.3) ** 2 + (y - h * 0.4) ** 2 < (h * 0.3) ** 2:
+                c = (200, 30, 30)
+            if w * 0.55 < x < w * 0.9 and h * 0.2 < y < h * 0.7:
+                c = (30, 60, 180)
+            if abs((x - w * 0.5) - (y - h * 0.8) * 2) < 4:
+                c = (20, 20, 20)
+            out.append(c)
+    return out
+
+
+def noise(w, h, seed):
+    r = lcg(seed)
+    return [(next(r) & 255, next(r) & 255, next(r) & 255) for _ in range(w * h)]
+
+
+def translucent(w, h):
+    out = []
+    for y in range(h):
+        for x in range(w):
+            a = 255 if x < w // 2 else (y * 255 // (h - 1))
+            out.append((x * 2 & 255, 255 - y * 2 & 255, (x * y) & 255, a))
+    return out
+
+
+def blobs(w, h, seed):
+    r = lcg(seed)
+    centres = [(next(r) % w, next(r) % h, 20 + next(r) % 80, next(r) % 256) for _ in range(12)]
+    out = []
+    for y in range(h):
+        for x in range(w):
+            v = 40.0
+            for cx, cy, rad, tone in centres:
+                d2 = (x - cx) ** 2 + (y - cy) ** 2
+                if d2 < rad * rad:
+                    v = tone + 20 * math.sin(x / 3.0) * math.cos(y / 5.0)
+            out.append((clamp(v), clamp(v * 0.8 + 30), clamp(255 - v)))
+    return out
+
+
+FIXTURES = [
+    ("gradient-64x64.png", 64, 64, lambda: gradient(64, 64), False),
+    ("waves-300x200.png", 300, 200, lambda: waves(300, 200), False),
+    ("shapes-257x129.png", 257, 129, lambda: shapes(257, 129), False),
+    ("noise-96x96.png", 96, 96, lambda: noise(96, 96, 43), False),
+    ("blobs-512x384.png", 512, 384, lambda: blobs(512, 384, 7), False),
+    ("strip-1000x50.png", 1000, 50, lambda: waves(1000, 50), False),
+    ("translucent-120x90.png", 120, 90, lambda: translucent(120, 90), True),
+    ("tiny-4x9.png", 4, 9, lambda: noise(4, 9, 5), False),
+]
+
+
+def main():
+    hashes, dihedral = [], []
+    for name, w, h, draw, alpha in FIXTURES:
+        pixels = draw()
+        write_png(name, w, h, pixels, alpha)
+        # the reference reads the first three channels, ignoring alpha
+        variants, quality = pdq_hashes([p[:3] for p in pixels], h, w)
+        hashes.append("%s,%d,%s" % (variants[0], quality, name))
+        dihedral.append(",".join([name] + variants))
+    with open("hashes.csv", "w") as f:
+        f.write("\n".join(hashes) + "\n")
+    with open("dihedral.csv", "w") as f:
+        f.write("\n".join(dihedral) + "\n")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,67 @@
+package imagehash
+
+import (
+	"image"
+	"math/bits"
+
+	"go.local/go-image-phash/wavelet"
+)
+
+// whashSize is the side of the approximation band wHash keeps, hash_size in
+// Python's imagehash.
+const whashSize = 8
+
+// WaveletHash returns the wHash of img as Python imagehash's whash with its
+// defaults, hash_size 8 and mode "haar": the grayscale, scaled to the power
+// of 2 side at or below its shorter one, is stripped of its coarsest Haar
+// approximation, the mean, and the approximation band of the level leaving
+// 8x8 coefficients is split at its median.
+func WaveletHash(img image.Image) (*ImageHash, error) {
+	return waveletHash(img, wavelet.Haar, WHash)
+}
+
+// WaveletHashD4 is WaveletHash with mode "db2", the Daubechies-4 wavelet, for
+// the band the hash is taken from. See package wavelet for how its borders
+// differ from PyWavelets'.
+func WaveletHashD4(img image.Image) (*ImageHash, error) {
+	return waveletHash(img, wavelet.D4, WHashD4)
+}
+
+func waveletHash(img image.Image, w wavelet.Wavelet, kind Kind) (*ImageHash, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	// image_scale = max(2**int(log2(min(size))), hash_size)
+	scale := max(1<<(bits.Len(uint(min(b.Dx(), b.Dy())))-1), whashSize)
+	llMaxLevel := bits.Len(uint(scale)) - 1
+	level := bits.Len(uint(whashSize)) - 1
+
+	pixels := grayscale(img, scale, scale)
+	for i := range pixels {
+		pixels[i] /= 255
+	}
+
+	// remove_max_haar_ll
+	if err := wavelet.Forward2D(pixels, scale, scale, llMaxLevel, wavelet.Haar); err != nil {
+		return nil, err
+	}
+	pixels[0] = 0
+	if err := wavelet.Inverse2D(pixels, scale, scale, llMaxLevel, wavelet.Haar); err != nil {
+		return nil, err
+	}
+
+	if err := wavelet.Forward2D(pixels, scale, scale, llMaxLevel-level, w); err != nil {
+		return nil, err
+	}
+	low := make([]float64, 0, whashSize*whashSize)
+	for y := 0; y < whashSize; y++ {
+		low = append(low, pixels[y*scale:y*scale+whashSize]...)
+	}
+
+	return NewImageHash(hashAbove(low, medianOf(low)), kind), nil
+}
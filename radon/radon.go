@@ -0,0 +1,97 @@
+// Package radon samples the discrete Radon projections of an image, the
+// pixels along lines through its centre at evenly spaced angles, as pHash's
+// ph_radon_projections.
+//
+// The line at angle k*pi/n is walked one pixel per step along its major
+// axis, so every line of an image of side D has at most D samples, and
+// Count records how many fell inside the image. Positions are rounded as
+// pHash's ROUNDING_FACTOR does, so the samples are the same.
+package radon
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrInvalidInput  = errors.New("radon: invalid input")
+	ErrInvalidAngles = errors.New("radon: number of angles is not a positive multiple of 4")
+)
+
+// Projections holds the n projections of a w x h image, D = max(w, h).
+type Projections struct {
+	N, D int
+
+	// Lines holds n x D samples, Lines[k*D+i] being sample i on the line at
+	// angle k*pi/N. Samples that fall outside the image are 0.
+	Lines []float64
+
+	// Count holds the number of samples inside the image on each line.
+	Count []int
+}
+
+// Line returns the D samples of the line at angle k*pi/N.
+func (p *Projections) Line(k int) []float64 {
+	return p.Lines[k*p.D : (k+1)*p.D]
+}
+
+// Project returns the n projections of the w x h pixels in row order. n must
+// be a multiple of 4, so the diagonals fall on lines; pHash uses 180.
+func Project(pixels []float64, w, h, n int) (*Projections, error) {
+	if w <= 0 || h <= 0 || len(pixels) != w*h {
+		return nil, ErrInvalidInput
+	}
+	if n <= 0 || n%4 != 0 {
+		return nil, ErrInvalidAngles
+	}
+
+	d := max(w, h)
+	p := &Projections{N: n, D: d, Lines: make([]float64, n*d), Count: make([]int, n)}
+	x_off := round(float64(w) / 2)
+	y_off := round(float64(h) / 2)
+
+	set := func(k, i, x, y int) {
+		p.Lines[k*d+i] = pixels[y*w+x]
+		p.Count[k]++
+	}
+
+	// Angles up to pi/4 step along x, and their reflections about the
+	// diagonal, pi/2 - theta, along y.
+	for k := 0; k <= n/4; k++ {
+		alpha := math.Tan(float64(k) * math.Pi / float64(n))
+		for x := 0; x < d; x++ {
+			yd := round(alpha * float64(x-x_off))
+			if yd+y_off >= 0 && yd+y_off < h && x < w {
+				set(k, x, x, yd+y_off)
+			}
+			if yd+x_off >= 0 && yd+x_off < w && k != n/4 && x < h {
+				set(n/2-k, x, yd+x_off, x)
+			}
+		}
+	}
+
+	// Angles from 3pi/4 step along x, and their reflections about the
+	// anti-diagonal, 3pi/2 - theta, along y.
+	for k, j := 3*n/4, 0; k < n; k, j = k+1, j+2 {
+		alpha := math.Tan(float64(k) * math.Pi / float64(n))
+		for x := 0; x < d; x++ {
+			yd := round(alpha * float64(x-x_off))
+			if yd+y_off >= 0 && yd+y_off < h && x < w {
+				set(k, x, x, yd+y_off)
+			}
+			if y_off-yd >= 0 && y_off-yd < w && 2*y_off-x >= 0 && 2*y_off-x < h && k != 3*n/4 {
+				set(k-j, x, y_off-yd, 2*y_off-x)
+			}
+		}
+	}
+	return p, nil
+}
+
+// round is pHash's floor(y + ROUNDING_FACTOR(y)): the nearest integer for
+// y >= 0, but floor(y - 0.5) below, which is one less for most negative y.
+func round(y float64) int {
+	if y >= 0 {
+		return int(math.Floor(y + 0.5))
+	}
+	return int(math.Floor(y - 0.5))
+}
@@ -0,0 +1,102 @@
+package radon
+
+import (
+	"math"
+	"testing"
+)
+
+const (
+	EPSILON float64 = 0.00000001
+)
+
+// ramp returns a w x h image whose pixel (x, y) is 1000 y + x.
+func ramp(w, h int) []float64 {
+	pixels := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pixels[y*w+x] = float64(1000*y + x)
+		}
+	}
+	return pixels
+}
+
+func TestProject(t *testing.T) {
+	const w, h, n = 8, 8, 180
+	p, err := Project(ramp(w, h), w, h, n)
+	if err != nil {
+		t.Fatalf("Project returned error %v", err)
+	}
+
+	for _, tt := range []struct {
+		k     int
+		x, y  func(i int) int
+		count int
+	}{
+		// the horizontal and vertical lines through (4, 4)
+		{0, func(i int) int { return i }, func(int) int { return 4 }, 8},
+		{n / 2, func(int) int { return 4 }, func(i int) int { return i }, 8},
+		// the diagonal, shifted down by pHash's rounding left of the
+		// centre, which leaves sample 0 outside
+		{n / 4, func(i int) int { return i }, func(i int) int { return i - 1 + min(i/4, 1) }, 7},
+	} {
+		if p.Count[tt.k] != tt.count {
+			t.Errorf("line %d has %d samples, expected %d.", tt.k, p.Count[tt.k], tt.count)
+		}
+		for i, v := range p.Line(tt.k) {
+			if tt.y(i) < 0 {
+				continue
+			}
+			if want := float64(1000*tt.y(i) + tt.x(i)); math.Abs(v-want) > EPSILON {
+				t.Errorf("line %d sample %d is %v, expected %v.", tt.k, i, v, want)
+			}
+		}
+	}
+
+	for k, c := range p.Count {
+		if c == 0 || c > p.D {
+			t.Errorf("line %d has %d samples.", k, c)
+		}
+	}
+}
+
+func TestProjectCoversAngles(t *testing.T) {
+	// a flat image has each line filled with 1 exactly where it was sampled
+	const w, h, n = 31, 17, 36
+	pixels := make([]float64, w*h)
+	for i := range pixels {
+		pixels[i] = 1
+	}
+	p, err := Project(pixels, w, h, n)
+	if err != nil {
+		t.Fatalf("Project returned error %v", err)
+	}
+	if p.D != w || len(p.Lines) != n*w {
+		t.Fatalf("Project returned D %d and %d samples.", p.D, len(p.Lines))
+	}
+	for k := 0; k < n; k++ {
+		var sum float64
+		for _, v := range p.Line(k) {
+			sum += v
+		}
+		if p.Count[k] == 0 || int(sum) != p.Count[k] {
+			t.Errorf("line %d sums to %v over %d samples.", k, sum, p.Count[k])
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		pixels  []float64
+		w, h, n int
+		err     error
+	}{
+		{nil, 0, 0, 4, ErrInvalidInput},
+		{make([]float64, 6), 2, 2, 4, ErrInvalidInput},
+		{make([]float64, 4), 2, 2, 0, ErrInvalidAngles},
+		{make([]float64, 4), 2, 2, 6, ErrInvalidAngles},
+	} {
+		if _, err := Project(tt.pixels, tt.w, tt.h, tt.n); err != tt.err {
+			t.Errorf("Project(%d x %d, %d) expected error %v but got %v.", tt.w, tt.h, tt.n, tt.err, err)
+		}
+	}
+}
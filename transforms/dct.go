@@ -6,145 +6,187 @@ package transforms
 
 import (
 	"math"
+	"runtime"
 	"sync"
+
+	"go.local/go-image-phash/dct"
 )
 
 // DCT1D function returns result of DCT-II.
 // DCT type II, unscaled. Algorithm by Byeong Gi Lee, 1984.
 func DCT1D(input []float64) []float64 {
-	temp := make([]float64, len(input))
-	forwardTransform(input, temp, len(input))
-	return input
+	return dct1D(input)
 }
 
-func forwardTransform(input, temp []float64, Len int) {
-	if Len == 1 {
-		return
-	}
+// DCT1DF32 function returns result of DCT-II in float32.
+func DCT1DF32(input []float32) []float32 {
+	return dct1D(input)
+}
 
-	halfLen := Len / 2
-	for i := 0; i < halfLen; i++ {
-		x, y := input[i], input[Len-1-i]
-		temp[i] = x + y
-		temp[i+halfLen] = (x - y) / (math.Cos((float64(i)+0.5)*math.Pi/float64(Len)) * 2)
-	}
-	forwardTransform(temp, input, halfLen)
-	forwardTransform(temp[halfLen:], input, halfLen)
-	for i := 0; i < halfLen-1; i++ {
-		input[i*2+0] = temp[i]
-		input[i*2+1] = temp[i+halfLen] + temp[i+halfLen+1]
-	}
-	input[Len-2], input[Len-1] = temp[halfLen-1], temp[Len-1]
+func dct1D[T dct.Float](input []T) []T {
+	dct.NewDCT1DKernel[T](len(input))(input)
+	return input
 }
 
 // DCT2D function returns a  result of DCT2D by using the separable property.
+// input is left untouched.
 func DCT2D(input [][]float64, w int, h int) [][]float64 {
-	output := make([][]float64, h)
+	return dct2D(input, w, h)
+}
+
+// DCT2DF32 function returns a result of DCT2D in float32.
+func DCT2DF32(input [][]float32, w int, h int) [][]float32 {
+	return dct2D(input, w, h)
+}
+
+// parallelThreshold is the smallest w*h for which dct2D shares the rows out
+// over a worker pool; below it the goroutines cost more than they save.
+var parallelThreshold = 128 * 128
+
+// transposeBlock is the tile size of transpose, 32x32 float64 fit in L1.
+const transposeBlock = 32
+
+func dct2D[T dct.Float](input [][]T, w int, h int) [][]T {
+	buf := make([]T, w*h)
+	for i := 0; i < h; i++ {
+		copy(buf[i*w:(i+1)*w], input[i])
+	}
+	temp := make([]T, w*h)
+
+	transformRows(buf, w, h)
+	transpose(temp, buf, w, h)
+	transformRows(temp, h, w)
+	transpose(buf, temp, h, w)
+
+	output := make([][]T, h)
 	for i := range output {
-		output[i] = make([]float64, w)
+		output[i] = buf[i*w : (i+1)*w : (i+1)*w]
 	}
+	return output
+}
 
-	wg := new(sync.WaitGroup)
-	for i := 0; i < h; i++ {
-		wg.Add(1)
-		go func(i int) {
-			cols := DCT1D(input[i])
-			output[i] = cols
-			wg.Done()
-		}(i)
+// transformRows runs the dct kernel over each of the rows of length Len in
+// buf, on a pool of up to GOMAXPROCS workers above parallelThreshold.
+func transformRows[T dct.Float](buf []T, Len, rows int) {
+	if len(buf) < parallelThreshold {
+		kernel := dct.NewDCT1DKernel[T](Len)
+		for r := 0; r < rows; r++ {
+			kernel(buf[r*Len : (r+1)*Len])
+		}
+		return
 	}
 
-	wg.Wait()
-	for i := 0; i < w; i++ {
+	workers := min(runtime.GOMAXPROCS(0), rows)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
-		in := make([]float64, h)
-		go func(i int) {
-			for j := 0; j < h; j++ {
-				in[j] = output[j][i]
-			}
-			rows := DCT1D(in)
-			for j := 0; j < len(rows); j++ {
-				output[j][i] = rows[j]
+		go func(w int) {
+			defer wg.Done()
+			kernel := dct.NewDCT1DKernel[T](Len) // kernels keep scratch space
+			for r := w; r < rows; r += workers {
+				kernel(buf[r*Len : (r+1)*Len])
 			}
-			wg.Done()
-		}(i)
+		}(w)
 	}
-
 	wg.Wait()
-	return output
 }
 
-// DCT2DFast64 function returns a result of DCT2D by using the separable property.
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
-// Added by lbe 2024-07-26
-func DCT2DFast32(input *[]float64) (flattens [64]float64) {
-	if len(*input) != 64*64 {
-		panic("incorrect input size, wanted 64x64.")
+// transpose writes the rows x cols matrix src to dst as cols x rows, a tile
+// at a time so both sides stay in cache.
+func transpose[T dct.Float](dst, src []T, cols, rows int) {
+	for y0 := 0; y0 < rows; y0 += transposeBlock {
+		for x0 := 0; x0 < cols; x0 += transposeBlock {
+			for y := y0; y < min(y0+transposeBlock, rows); y++ {
+				for x := x0; x < min(x0+transposeBlock, cols); x++ {
+					dst[x*rows+y] = src[y*cols+x]
+				}
+			}
+		}
 	}
+}
 
-	for i := 0; i < 32; i++ { // height
-		forwardDCT32((*input)[i*32 : (i*32)+32])
+// DCT2DFast32 returns the 8x8 low frequency corner of the 2D DCT-II of a
+// flattened 32x32 input, flattens[8*v+u] = dct.DCT2DFast32(input)[32*v+u].
+// *input is left untouched. Panics if input is not 32x32.
+//
+// Deprecated: use dct.PartialDCT2D(input, 32, 8), which this calls.
+func DCT2DFast32(input *[]float64) (flattens [64]float64) {
+	flattens, err := DCT2DFast32E(input)
+	if err != nil {
+		panic(err)
 	}
+	return flattens
+}
 
-	var row [32]float64
-	for i := 0; i < 8; i++ { // width
-		for j := 0; j < 32; j++ {
-			row[j] = (*input)[32*j+i]
-		}
-		forwardDCT32(row[:])
-		for j := 0; j < 8; j++ {
-			flattens[8*j+i] = row[j]
-		}
-	}
-	return
+// DCT2DFast32E is DCT2DFast32 returning an error instead of panicking on an
+// input that is not 32x32.
+//
+// Deprecated: use dct.PartialDCT2D(input, 32, 8).
+func DCT2DFast32E(input *[]float64) (flattens [64]float64, err error) {
+	err = partialDCT2D(flattens[:], input, "DCT2DFast32", 32, 8)
+	return flattens, err
 }
 
-// DCT2DFast64 function returns a result of DCT2D by using the separable property.
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
+// DCT2DFast64 returns the 2D DCT-II of a flattened 64x64 input, laid out as
+// dct.DCT2DFast64, flattens[64*v+u]. *input is left untouched. Panics if
+// input is not 64x64.
+//
+// Deprecated: use dct.DCT2DFast64.
 func DCT2DFast64(input *[]float64) (flattens [64 * 64]float64) {
-	if len(*input) != 64*64 {
-		panic("incorrect input size, wanted 64x64.")
+	flattens, err := DCT2DFast64E(input)
+	if err != nil {
+		panic(err)
 	}
+	return flattens
+}
 
-	for i := 0; i < 64; i++ { // height
-		forwardDCT64((*input)[i*64 : (i*64)+64])
-	}
+// DCT2DFast64E is DCT2DFast64 returning an error instead of panicking on an
+// input that is not 64x64.
+//
+// Deprecated: use dct.DCT2DFast64E.
+func DCT2DFast64E(input *[]float64) (flattens [64 * 64]float64, err error) {
+	err = partialDCT2D(flattens[:], input, "DCT2DFast64", 64, 64)
+	return flattens, err
+}
 
-	var row [64]float64
-	for i := 0; i < 8; i++ { // width
-		for j := 0; j < 64; j++ {
-			row[j] = (*input)[64*j+i]
-		}
-		forwardDCT64(row[:])
-		for j := 0; j < 64; j++ {
-			flattens[8*j+i] = row[j]
-		}
+// DCT2DFast256 returns the 16x16 low frequency corner of the 2D DCT-II of a
+// flattened 256x256 input, flattens[16*v+u] = dct.DCT2DFast256(input)[256*v+u].
+// *input is left untouched. Panics if input is not 256x256.
+//
+// Deprecated: use dct.PartialDCT2D(input, 256, 16), which this calls.
+func DCT2DFast256(input *[]float64) (flattens [256]float64) {
+	flattens, err := DCT2DFast256E(input)
+	if err != nil {
+		panic(err)
 	}
-	return
+	return flattens
 }
 
-// DCT2DFast256 function returns a result of DCT2D by using the separable property.
-// DCT type II, unscaled. Algorithm by Byeong Gi Lee, 1984.
-// Fast uses static DCT tables for improved performance. Returns flattened pixels.
-func DCT2DFast256(input *[]float64) (flattens [256]float64) {
-	if len(*input) != 256*256 {
-		panic("incorrect input size, wanted 256x256.")
+// DCT2DFast256E is DCT2DFast256 returning an error instead of panicking on an
+// input that is not 256x256.
+//
+// Deprecated: use dct.PartialDCT2D(input, 256, 16).
+func DCT2DFast256E(input *[]float64) (flattens [256]float64, err error) {
+	err = partialDCT2D(flattens[:], input, "DCT2DFast256", 256, 16)
+	return flattens, err
+}
+
+// partialDCT2D writes the k x k corner of the 2D DCT-II of the n x n *input
+// to dst, with the errors of the dct package's DCT2DFastNE.
+func partialDCT2D(dst []float64, input *[]float64, name string, n, k int) error {
+	if input == nil || len(*input) == 0 {
+		return dct.ErrInvalidInput
 	}
-	for i := 0; i < 256; i++ { // height
-		forwardDCT256((*input)[i*256 : 256*i+256])
+	if len(*input) != n*n {
+		return &dct.SizeError{Func: name, Size: n, Len: len(*input)}
 	}
 
-	var row [256]float64
-	for i := 0; i < 16; i++ { // width
-		for j := 0; j < 256; j++ {
-			row[j] = (*input)[256*j+i]
-		}
-		forwardDCT256(row[:])
-		for j := 0; j < 256; j++ {
-			flattens[16*j+i] = row[j]
-		}
+	corner, err := dct.PartialDCT2D(*input, n, k)
+	if err != nil {
+		return err
 	}
-	return flattens
+	copy(dst, corner)
+	return nil
 }
 
 func Naive_perl_dct1d(vector []float64) []float64 {
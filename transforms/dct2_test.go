@@ -5,8 +5,15 @@
 package transforms
 
 import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
 	"math/rand"
+	"slices"
 	"testing"
+
+	"go.local/go-image-phash/dct"
 )
 
 const (
@@ -69,7 +76,7 @@ func TestDCT1D(t *testing.T) {
 		{ary[64], exp[64]},
 	} {
 
-		out := DCT1D(tt.input)
+		out := DCT1D(slices.Clone(tt.input)) // DCT1D works in place
 		pass := true
 
 		if len(tt.output) != len(out) {
@@ -144,14 +151,442 @@ func TestDCT2D(t *testing.T) {
 	}
 }
 
+func TestDCT2DF32(t *testing.T) {
+	for _, n := range []int{4, 8, 32, 64} {
+		in := make([][]float64, n)
+		in32 := make([][]float32, n)
+		l1 := 0.0
+		for i := range in {
+			in[i] = slices.Clone(ary2d[n][i])
+			in32[i] = make([]float32, n)
+			for j, v := range in[i] {
+				in32[i][j] = float32(v)
+				l1 += math.Abs(v)
+			}
+		}
+		out := DCT2D(in, n, n)
+		out32 := DCT2DF32(in32, n, n)
+		tolerance := 1.2e-7 * l1
+
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if math.Abs(out[i][j]-float64(out32[i][j])) > tolerance {
+					t.Fatalf("DCT2DF32(%d)[%d][%d] = %v, float64 gives %v, tolerance %v.", n, i, j, out32[i][j], out[i][j], tolerance)
+				}
+			}
+		}
+	}
+}
+
+func TestDCT2DFastE(t *testing.T) {
+	r := rand.New(rand.NewSource(32))
+	in256 := make([][]float64, 256)
+	for i := range in256 {
+		in256[i] = make([]float64, 256)
+		for j := range in256[i] {
+			in256[i][j] = r.Float64()
+		}
+	}
+
+	for _, tt := range []struct {
+		input  [][]float64
+		n      int
+		corner int
+		fast   func(*[]float64) ([]float64, error)
+	}{
+		{ary2d[32], 32, 8, func(in *[]float64) ([]float64, error) { out, err := DCT2DFast32E(in); return out[:], err }},
+		{in256, 256, 16, func(in *[]float64) ([]float64, error) { out, err := DCT2DFast256E(in); return out[:], err }},
+	} {
+		expect := Naive_perl_dct2d(tt.input)
+		flat := FlattenPixels(tt.input, tt.n, tt.n)
+		out, err := tt.fast(&flat)
+		if err != nil {
+			t.Fatalf("DCT2DFast%dE returned error %v", tt.n, err)
+		}
+		for i := 0; i < tt.corner; i++ {
+			for j := 0; j < tt.corner; j++ {
+				if math.Abs(out[i*tt.corner+j]-expect[i][j]) > EPSILON {
+					t.Fatalf("DCT2DFast%dE[%d][%d] expected %v but got %v.", tt.n, i, j, expect[i][j], out[i*tt.corner+j])
+				}
+			}
+		}
+
+		if _, err := tt.fast(nil); err != dct.ErrInvalidInput {
+			t.Errorf("DCT2DFast%dE(nil) expected error %v but got %v.", tt.n, dct.ErrInvalidInput, err)
+		}
+		wrong := make([]float64, 64*64)
+		if _, err := tt.fast(&wrong); !errors.Is(err, dct.ErrInvalidSize) {
+			t.Errorf("DCT2DFast%dE of 64x64 values expected error %v but got %v.", tt.n, dct.ErrInvalidSize, err)
+		}
+	}
+}
+
+func TestDCT2DParallel(t *testing.T) {
+	defer func(threshold int) { parallelThreshold = threshold }(parallelThreshold)
+
+	input := randomSquare(256)
+	orig := make([][]float64, len(input))
+	for i := range input {
+		orig[i] = slices.Clone(input[i])
+	}
+
+	parallelThreshold = math.MaxInt
+	serial := DCT2D(input, 256, 256)
+	parallelThreshold = 0
+	parallel := DCT2D(input, 256, 256)
+
+	for i := range input {
+		if !slices.Equal(input[i], orig[i]) {
+			t.Fatalf("DCT2D modified row %d of its input.", i)
+		}
+		if !slices.Equal(serial[i], parallel[i]) {
+			t.Fatalf("DCT2D row %d is %v serially but %v in parallel.", i, serial[i], parallel[i])
+		}
+	}
+
+	expect := Naive_perl_dct2d(input)
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			if math.Abs(parallel[i][j]-expect[i][j]) > 1e-6 {
+				t.Fatalf("DCT2D[%d][%d] expected %v but got %v.", i, j, expect[i][j], parallel[i][j])
+			}
+		}
+	}
+}
+
+// TestAgreesWithDCT checks transforms against the dct package wherever their
+// APIs overlap, DCT1D and DCT2D for any size and the DCT2DFastN wrappers as
+// corners of the dct layout.
+func TestAgreesWithDCT(t *testing.T) {
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) <= EPSILON*max(1, math.Abs(b))
+	}
+
+	for _, n := range []int{1, 2, 3, 8, 11, 16, 32, 48, 64, 100, 256} {
+		input := randomSquare(n)
+		flat := FlattenPixels(input, n, n)
+
+		out1d := DCT1D(slices.Clone(input[0]))
+		expect1d := dct.DCT_1D(input[0], n)
+		for i := range out1d {
+			if !near(out1d[i], expect1d[i]) {
+				t.Fatalf("DCT1D(%d)[%d] = %v, dct.DCT_1D gives %v.", n, i, out1d[i], expect1d[i])
+			}
+		}
+
+		out2d := DCT2D(input, n, n)
+		expect2d := dct.DCT_2D(flat, n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if !near(out2d[i][j], expect2d[i*n+j]) {
+					t.Fatalf("DCT2D(%d)[%d][%d] = %v, dct.DCT_2D gives %v.", n, i, j, out2d[i][j], expect2d[i*n+j])
+				}
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		n, corner int
+		fast      func(*[]float64) []float64
+		expect    func([]float64) []float64
+	}{
+		{32, 8,
+			func(in *[]float64) []float64 { out := DCT2DFast32(in); return out[:] },
+			func(in []float64) []float64 { out := dct.DCT2DFast32(in); return out[:] }},
+		{64, 64,
+			func(in *[]float64) []float64 { out := DCT2DFast64(in); return out[:] },
+			func(in []float64) []float64 { out := dct.DCT2DFast64(in); return out[:] }},
+		{256, 16,
+			func(in *[]float64) []float64 { out := DCT2DFast256(in); return out[:] },
+			func(in []float64) []float64 { out := dct.DCT2DFast256(in); return out[:] }},
+	} {
+		flat := FlattenPixels(randomSquare(tt.n), tt.n, tt.n)
+		orig := slices.Clone(flat)
+
+		out := tt.fast(&flat)
+		if !slices.Equal(flat, orig) {
+			t.Fatalf("DCT2DFast%d modified its input.", tt.n)
+		}
+
+		expect := tt.expect(flat)
+		for v := 0; v < tt.corner; v++ {
+			for u := 0; u < tt.corner; u++ {
+				if !near(out[tt.corner*v+u], expect[tt.n*v+u]) {
+					t.Fatalf("DCT2DFast%d[%d][%d] = %v, dct.DCT2DFast%d gives %v.", tt.n, v, u, out[tt.corner*v+u], tt.n, expect[tt.n*v+u])
+				}
+			}
+		}
+	}
+}
+
+func TestRgb2GrayFastRect(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			img.Set(x, y, color.RGBA{uint8(40 * x), uint8(50 * y), uint8(x + y), 255})
+		}
+	}
+	ycbcr := image.NewYCbCr(img.Bounds(), image.YCbCrSubsampleRatio444)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			i := ycbcr.YOffset(x, y)
+			ycbcr.Y[i], ycbcr.Cb[i], ycbcr.Cr[i] = color.RGBToYCbCr(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		}
+	}
+
+	for _, src := range []image.Image{img, ycbcr, img.SubImage(image.Rect(2, 1, 5, 4)), ycbcr.SubImage(image.Rect(2, 1, 5, 4))} {
+		b := src.Bounds()
+		expect := make([]float64, b.Dx()*b.Dy())
+		for i := range expect {
+			expect[i] = pixel2Gray(src.At(b.Min.X+i%b.Dx(), b.Min.Y+i/b.Dx()).RGBA())
+		}
+		pixels := make([]float64, b.Dx()*b.Dy())
+		Rgb2GrayFastRect(src, &pixels)
+		if !slices.Equal(pixels, expect) {
+			t.Errorf("Rgb2GrayFastRect of %T %v is %v, expected %v.", src, b, pixels, expect)
+		}
+	}
+}
+
+func TestColorPlanes(t *testing.T) {
+	pixels := []color.RGBA{
+		{255, 255, 255, 255},
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{255, 0, 255, 255},
+		{102, 102, 102, 255},
+	}
+	img := image.NewRGBA(image.Rect(3, 5, 3+len(pixels), 6))
+	for i, c := range pixels {
+		img.SetRGBA(3+i, 5, c)
+	}
+
+	n := len(pixels)
+	y, cb, cr := make([]float64, n), make([]float64, n), make([]float64, n)
+	Rgb2YCbCrRect(img, y, cb, cr)
+	h, s, v := make([]float64, n), make([]float64, n), make([]float64, n)
+	Rgb2HSVRect(img, h, s, v)
+
+	for i, tt := range []struct {
+		y, cb, cr float64
+		h, s, v   float64
+	}{
+		{255, 128, 128, 0, 0, 1},
+		{0.299 * 255, 128 - 0.168736*255, 255.5, 0, 1, 1},
+		{0.587 * 255, 128 - 0.331264*255, 128 - 0.418688*255, 1.0 / 3, 1, 1},
+		{0.114 * 255, 255.5, 128 - 0.081312*255, 2.0 / 3, 1, 1},
+		{(0.299 + 0.114) * 255, 128 + 0.331264*255, 128 + 0.418688*255, 5.0 / 6, 1, 1},
+		{102, 128, 128, 0, 0, 0.4},
+	} {
+		got := []float64{y[i], cb[i], cr[i], h[i], s[i], v[i]}
+		want := []float64{tt.y, tt.cb, tt.cr, tt.h, tt.s, tt.v}
+		for j := range got {
+			if math.Abs(got[j]-want[j]) > EPSILON {
+				t.Errorf("planes of %v are %v, expected %v.", pixels[i], got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestLoGKernel(t *testing.T) {
+	kernel, size := LoGKernel(2, 1)
+	if size != 17 || len(kernel) != 17*17 {
+		t.Fatalf("LoGKernel(2, 1) is %d long, size %d, expected 17x17.", len(kernel), size)
+	}
+	for _, tt := range []struct {
+		x, y   int
+		expect float64
+	}{
+		{8, 8, 2},
+		{10, 8, math.Exp(-0.5)},    // r = 1
+		{8, 6, math.Exp(-0.5)},     // r = 1
+		{10, 10, 0},                // r^2 = 2
+		{0, 8, -14 * math.Exp(-8)}, // r = 4
+	} {
+		if got := kernel[tt.y*size+tt.x]; math.Abs(got-tt.expect) > EPSILON {
+			t.Errorf("LoGKernel(2, 1)[%d][%d] is %v, expected %v.", tt.y, tt.x, got, tt.expect)
+		}
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	r := rand.New(rand.NewSource(44))
+	w, h, size := 13, 9, 5
+	pixels := make([]float64, w*h)
+	for i := range pixels {
+		pixels[i] = r.Float64()
+	}
+	kernel := make([]float64, size*size)
+	for i := range kernel {
+		kernel[i] = r.Float64() - 0.5
+	}
+
+	out := Correlate(pixels, w, h, kernel, size)
+	clamp := func(v, n int) int { return min(max(v, 0), n-1) }
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var expect float64
+			for q := 0; q < size; q++ {
+				for p := 0; p < size; p++ {
+					expect += pixels[clamp(y+q-size/2, h)*w+clamp(x+p-size/2, w)] * kernel[q*size+p]
+				}
+			}
+			if math.Abs(out[y*w+x]-expect) > EPSILON {
+				t.Fatalf("Correlate[%d][%d] is %v, expected %v.", y, x, out[y*w+x], expect)
+			}
+		}
+	}
+}
+
+func TestGaussianBlur(t *testing.T) {
+	r := rand.New(rand.NewSource(46))
+	w, h, sigma := 17, 11, 1.5
+	pixels := make([]float64, w*h)
+	for i := range pixels {
+		pixels[i] = r.Float64()
+	}
+
+	// the separable passes equal the 2D Gaussian, truncated at 3 sigma
+	half := 5
+	size := 2*half + 1
+	kernel := make([]float64, size*size)
+	var sum float64
+	for q := 0; q < size; q++ {
+		for p := 0; p < size; p++ {
+			d2 := float64((q-half)*(q-half) + (p-half)*(p-half))
+			kernel[q*size+p] = math.Exp(-d2 / (2 * sigma * sigma))
+			sum += kernel[q*size+p]
+		}
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	out := GaussianBlur(pixels, w, h, sigma)
+	expect := Correlate(pixels, w, h, kernel, size)
+	for i := range out {
+		if math.Abs(out[i]-expect[i]) > EPSILON {
+			t.Fatalf("GaussianBlur[%d][%d] is %v, expected %v.", i/w, i%w, out[i], expect[i])
+		}
+	}
+
+	if out := GaussianBlur(pixels, w, h, 0); !slices.Equal(out, pixels) {
+		t.Errorf("GaussianBlur with sigma 0 changed the pixels.")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	// an impulse is removed and an edge kept, clamped at the borders
+	w, h := 6, 5
+	pixels := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 3; x < w; x++ {
+			pixels[y*w+x] = 9
+		}
+	}
+	pixels[2*w+1] = 100
+	out := Median(pixels, w, h, 3)
+	for i := range out {
+		expect := 0.0
+		if i%w >= 3 {
+			expect = 9
+		}
+		if out[i] != expect {
+			t.Fatalf("Median[%d][%d] is %v, expected %v.", i/w, i%w, out[i], expect)
+		}
+	}
+
+	if out := Median(pixels, w, h, 1); !slices.Equal(out, pixels) {
+		t.Errorf("Median of size 1 changed the pixels.")
+	}
+}
+
+func TestEqualize(t *testing.T) {
+	r := rand.New(rand.NewSource(45))
+	pixels := make([]float64, 10000)
+	for i := range pixels {
+		v := r.Float64()
+		pixels[i] = 10 + 200*v*v*v // skewed to the dark end
+	}
+	orig := slices.Clone(pixels)
+	Equalize(pixels, 256)
+
+	// monotonic, and the quartiles land near the quarters of the original
+	// range
+	for i := range pixels {
+		for _, j := range []int{(i + 1) % len(pixels), (i + 7) % len(pixels)} {
+			if orig[i] < orig[j] && pixels[i] > pixels[j] {
+				t.Fatalf("Equalize reordered %v and %v into %v and %v.", orig[i], orig[j], pixels[i], pixels[j])
+			}
+		}
+	}
+	lo, hi := slices.Min(orig), slices.Max(orig)
+	sorted := slices.Clone(pixels)
+	slices.Sort(sorted)
+	if sorted[len(sorted)-1] != hi {
+		t.Errorf("Equalize maximum is %v, expected %v.", sorted[len(sorted)-1], hi)
+	}
+	for q := 1; q < 4; q++ {
+		got := sorted[q*len(sorted)/4]
+		if expect := lo + (hi-lo)*float64(q)/4; math.Abs(got-expect) > 0.02*(hi-lo) {
+			t.Errorf("Equalize quartile %d is %v, expected about %v.", q, got, expect)
+		}
+	}
+
+	Normalize(pixels, 0, 1)
+	if slices.Min(pixels) != 0 || slices.Max(pixels) != 1 {
+		t.Errorf("Normalize range is %v to %v, expected 0 to 1.", slices.Min(pixels), slices.Max(pixels))
+	}
+}
+
+func randomSquare(n int) [][]float64 {
+	r := rand.New(rand.NewSource(int64(n)))
+	square := make([][]float64, n)
+	for i := range square {
+		square[i] = make([]float64, n)
+		for j := range square[i] {
+			square[i][j] = r.Float64() * 255
+		}
+	}
+	return square
+}
+
 func init() {
 	createTestData()
 }
 
-var dct [][]float64
+var result [][]float64
 
 func BenchmarkDCT2D(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		dct = DCT2D(ary2d[32], 32, 32)
+		result = DCT2D(ary2d[32], 32, 32)
+	}
+}
+
+func benchmarkDCT2D(b *testing.B, n, threshold int) {
+	defer func(threshold int) { parallelThreshold = threshold }(parallelThreshold)
+	parallelThreshold = threshold
+	input := randomSquare(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result = DCT2D(input, n, n)
+	}
+}
+
+func BenchmarkDCT2D_32_Parallel(b *testing.B) { benchmarkDCT2D(b, 32, 0) }
+func BenchmarkDCT2D_256(b *testing.B)         { benchmarkDCT2D(b, 256, parallelThreshold) }
+func BenchmarkDCT2D_256_Serial(b *testing.B)  { benchmarkDCT2D(b, 256, math.MaxInt) }
+func BenchmarkDCT2D_1024(b *testing.B)        { benchmarkDCT2D(b, 1024, parallelThreshold) }
+func BenchmarkDCT2D_1024_Serial(b *testing.B) { benchmarkDCT2D(b, 1024, math.MaxInt) }
+
+// BenchmarkDCT_2D_256 is the serial fast_dct_2d path of the dct package.
+func BenchmarkDCT_2D_256(b *testing.B) {
+	input := FlattenPixels(randomSquare(256), 256, 256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = dct.DCT_2D(input, 256)
 	}
 }
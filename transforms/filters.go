@@ -0,0 +1,192 @@
+// Copyright 2017 The goimagehash Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transforms
+
+import (
+	"math"
+	"slices"
+)
+
+// LoGKernel returns the size x size Marr-Hildreth kernel of pHash's
+// GetMHKernel, an unnormalised Laplacian of Gaussian (2 - r^2) exp(-r^2 / 2)
+// at the scale alpha^level, where size = 2 int(4 alpha^level) + 1. pHash's
+// ph_mh_imagehash uses alpha 2 and level 1, a 17x17 kernel.
+func LoGKernel(alpha, level float64) (kernel []float64, size int) {
+	sigma := int(4 * math.Pow(alpha, level))
+	size = 2*sigma + 1
+	scale := math.Pow(alpha, -level)
+
+	kernel = make([]float64, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			xpos := scale * float64(x-sigma)
+			ypos := scale * float64(y-sigma)
+			a := xpos*xpos + ypos*ypos
+			kernel[y*size+x] = (2 - a) * math.Exp(-a/2)
+		}
+	}
+	return kernel, size
+}
+
+// Correlate returns the correlation of the w x h pixels with the size x size
+// kernel centred on each pixel, repeating the edge pixels beyond the borders
+// as CImg's get_correlate with Neumann boundaries.
+func Correlate(pixels []float64, w, h int, kernel []float64, size int) []float64 {
+	out := make([]float64, w*h)
+	half := size / 2
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			inner := x >= half && x+size-half <= w && y >= half && y+size-half <= h
+			var sum float64
+			for q := 0; q < size; q++ {
+				yy := y + q - half
+				if !inner {
+					yy = min(max(yy, 0), h-1)
+				}
+				row := pixels[yy*w:]
+				k := kernel[q*size : (q+1)*size]
+				if inner {
+					row = row[x-half : x-half+size]
+					for p, kv := range k {
+						sum += row[p] * kv
+					}
+					continue
+				}
+				for p, kv := range k {
+					sum += row[min(max(x+p-half, 0), w-1)] * kv
+				}
+			}
+			out[y*w+x] = sum
+		}
+	}
+	return out
+}
+
+// Equalize spreads the histogram of pixels, in levels bins between their
+// minimum and maximum, evenly over that range in place, as CImg's equalize.
+func Equalize(pixels []float64, levels int) {
+	if len(pixels) == 0 || levels <= 0 {
+		return
+	}
+
+	lo, hi := pixels[0], pixels[0]
+	for _, p := range pixels {
+		lo, hi = min(lo, p), max(hi, p)
+	}
+	if lo == hi {
+		return
+	}
+
+	bin := func(p float64) int {
+		return int((p - lo) * (float64(levels) - 1) / (hi - lo))
+	}
+	cumul := make([]int, levels)
+	for _, p := range pixels {
+		cumul[bin(p)]++
+	}
+	for i := 1; i < levels; i++ {
+		cumul[i] += cumul[i-1]
+	}
+
+	total := float64(cumul[levels-1])
+	for i, p := range pixels {
+		pixels[i] = lo + (hi-lo)*float64(cumul[bin(p)])/total
+	}
+}
+
+// Normalize scales pixels in place linearly onto [lo, hi]. Constant pixels
+// become lo.
+func Normalize(pixels []float64, lo, hi float64) {
+	if len(pixels) == 0 {
+		return
+	}
+
+	vmin, vmax := pixels[0], pixels[0]
+	for _, p := range pixels {
+		vmin, vmax = min(vmin, p), max(vmax, p)
+	}
+	if vmin == vmax {
+		for i := range pixels {
+			pixels[i] = lo
+		}
+		return
+	}
+
+	scale := (hi - lo) / (vmax - vmin)
+	for i, p := range pixels {
+		pixels[i] = lo + (p-vmin)*scale
+	}
+}
+
+// GaussianBlur returns the w x h pixels blurred by a Gaussian of standard
+// deviation sigma, truncated at 3 sigma, repeating the edge pixels beyond the
+// borders. It stands in for CImg's blur, which approximates the same Gaussian
+// with a recursive filter.
+func GaussianBlur(pixels []float64, w, h int, sigma float64) []float64 {
+	out := make([]float64, w*h)
+	if sigma <= 0 {
+		copy(out, pixels)
+		return out
+	}
+
+	half := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*half+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - half)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	temp := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		row := pixels[y*w : (y+1)*w]
+		for x := 0; x < w; x++ {
+			var v float64
+			for i, k := range kernel {
+				v += row[min(max(x+i-half, 0), w-1)] * k
+			}
+			temp[y*w+x] = v
+		}
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var v float64
+			for i, k := range kernel {
+				v += temp[min(max(y+i-half, 0), h-1)*w+x] * k
+			}
+			out[y*w+x] = v
+		}
+	}
+	return out
+}
+
+// Median returns the w x h pixels with each replaced by the median of the
+// size x size window around it, size odd, repeating the edge pixels beyond
+// the borders as PIL's MedianFilter.
+func Median(pixels []float64, w, h, size int) []float64 {
+	out := make([]float64, w*h)
+	half := size / 2
+	window := make([]float64, 0, size*size)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			window = window[:0]
+			for q := -half; q <= half; q++ {
+				row := pixels[min(max(y+q, 0), h-1)*w:]
+				for p := -half; p <= half; p++ {
+					window = append(window, row[min(max(x+p, 0), w-1)])
+				}
+			}
+			slices.Sort(window)
+			out[y*w+x] = window[len(window)/2]
+		}
+	}
+	return out
+}
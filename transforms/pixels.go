@@ -30,17 +30,23 @@ func Rgb2Gray(colorImg image.Image) [][]float64 {
 // Rgb2GrayFast function converts RGB to a gray scale array.
 func Rgb2GrayFast(colorImg image.Image, pixels *[]float64) {
 	bounds := colorImg.Bounds()
-	w, h := bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y
-	if w != h {
+	if bounds.Dx() != bounds.Dy() {
 		return
 	}
+	Rgb2GrayFastRect(colorImg, pixels)
+}
+
+// Rgb2GrayFastRect is Rgb2GrayFast for a w x h image of any shape, writing the
+// w*h gray values row by row to *pixels, which must be at least that long.
+func Rgb2GrayFastRect(colorImg image.Image, pixels *[]float64) {
+	bounds := colorImg.Bounds()
 	switch c := colorImg.(type) {
 	case *image.YCbCr:
-		rgb2GrayYCbCR(c, *pixels, w)
+		rgb2GrayYCbCR(c, *pixels, bounds)
 	case *image.RGBA:
-		rgb2GrayRGBA(c, *pixels, w)
+		rgb2GrayRGBA(c, *pixels, bounds)
 	default:
-		rgb2GrayDefault(c, *pixels, w)
+		rgb2GrayDefault(c, *pixels, bounds)
 	}
 }
 
@@ -50,28 +56,83 @@ func pixel2Gray(r, g, b, a uint32) float64 {
 }
 
 // rgb2GrayDefault uses the image.Image interface
-func rgb2GrayDefault(colorImg image.Image, pixels []float64, s int) {
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			pixels[j+(i*s)] = pixel2Gray(colorImg.At(j, i).RGBA())
+func rgb2GrayDefault(colorImg image.Image, pixels []float64, r image.Rectangle) {
+	w := r.Dx()
+	for i := 0; i < r.Dy(); i++ {
+		for j := 0; j < w; j++ {
+			pixels[j+(i*w)] = pixel2Gray(colorImg.At(r.Min.X+j, r.Min.Y+i).RGBA())
 		}
 	}
 }
 
 // rgb2GrayYCbCR uses *image.YCbCr which is significantly faster than the image.Image interface.
-func rgb2GrayYCbCR(colorImg *image.YCbCr, pixels []float64, s int) {
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			pixels[j+(i*s)] = pixel2Gray(colorImg.YCbCrAt(j, i).RGBA())
+func rgb2GrayYCbCR(colorImg *image.YCbCr, pixels []float64, r image.Rectangle) {
+	w := r.Dx()
+	for i := 0; i < r.Dy(); i++ {
+		for j := 0; j < w; j++ {
+			pixels[j+(i*w)] = pixel2Gray(colorImg.YCbCrAt(r.Min.X+j, r.Min.Y+i).RGBA())
 		}
 	}
 }
 
 // rgb2GrayRGBA uses *image.RGBA which is significantly faster than the image.Image interface.
-func rgb2GrayRGBA(colorImg *image.RGBA, pixels []float64, s int) {
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			pixels[(i*s)+j] = pixel2Gray(colorImg.At(j, i).RGBA())
+func rgb2GrayRGBA(colorImg *image.RGBA, pixels []float64, r image.Rectangle) {
+	w := r.Dx()
+	for i := 0; i < r.Dy(); i++ {
+		for j := 0; j < w; j++ {
+			pixels[(i*w)+j] = pixel2Gray(colorImg.RGBAAt(r.Min.X+j, r.Min.Y+i).RGBA())
+		}
+	}
+}
+
+// Rgb2YCbCrRect converts a w x h image to the full range YCbCr of JPEG,
+// writing each plane row by row to y, cb and cr, which must be at least w*h
+// long. Y is the luminosity of Rgb2GrayFastRect; Cb and Cr are centred on
+// 128.
+func Rgb2YCbCrRect(colorImg image.Image, y, cb, cr []float64) {
+	bounds := colorImg.Bounds()
+	w := bounds.Dx()
+	for i := 0; i < bounds.Dy(); i++ {
+		for j := 0; j < w; j++ {
+			r, g, b, a := colorImg.At(bounds.Min.X+j, bounds.Min.Y+i).RGBA()
+			rf, gf, bf := float64(r)/257, float64(g)/257, float64(b)/257
+			y[j+(i*w)] = pixel2Gray(r, g, b, a)
+			cb[j+(i*w)] = 128 - 0.168736*rf - 0.331264*gf + 0.5*bf
+			cr[j+(i*w)] = 128 + 0.5*rf - 0.418688*gf - 0.081312*bf
+		}
+	}
+}
+
+// Rgb2HSVRect converts a w x h image to hue, saturation and value, writing
+// each plane row by row to h, s and v, which must be at least w*h long. All
+// three are in [0, 1], the hue as a fraction of the colour circle from red,
+// and 0 for grays.
+func Rgb2HSVRect(colorImg image.Image, h, s, v []float64) {
+	bounds := colorImg.Bounds()
+	w := bounds.Dx()
+	for i := 0; i < bounds.Dy(); i++ {
+		for j := 0; j < w; j++ {
+			r, g, b, _ := colorImg.At(bounds.Min.X+j, bounds.Min.Y+i).RGBA()
+			rf, gf, bf := float64(r)/65535, float64(g)/65535, float64(b)/65535
+			hi, lo := max(rf, gf, bf), min(rf, gf, bf)
+
+			var hue, sat float64
+			if c := hi - lo; c > 0 {
+				switch hi {
+				case rf:
+					hue = (gf - bf) / c
+					if hue < 0 {
+						hue += 6
+					}
+				case gf:
+					hue = (bf-rf)/c + 2
+				default:
+					hue = (rf-gf)/c + 4
+				}
+				hue /= 6
+				sat = c / hi
+			}
+			h[j+(i*w)], s[j+(i*w)], v[j+(i*w)] = hue, sat, hi
 		}
 	}
 }
@@ -0,0 +1,206 @@
+// Package wavelet implements multi-level orthonormal discrete wavelet
+// transforms, Haar and Daubechies-4, in 1D and separably in 2D.
+//
+// The transforms are in place. Each level splits the current approximation
+// into its low pass half, first, and its high pass half, so after levels
+// steps a 2D transform holds the approximation LL in its top left
+// rows>>levels x cols>>levels corner, surrounded by the detail bands of the
+// coarsest level and then the finer ones, as in Mallat's layout.
+//
+// Haar uses the filters of PyWavelets' "haar", so its coefficients are the
+// same as pywt.wavedec2 for sizes that are powers of 2. D4 extends the signal
+// periodically, keeping every level the same length and the transform
+// orthogonal, where PyWavelets' "db2" extends it symmetrically, so their
+// coefficients differ near the borders.
+package wavelet
+
+import (
+	"errors"
+	"math"
+)
+
+// Wavelet selects the wavelet of a transform.
+type Wavelet int
+
+const (
+	// Haar is the 2 tap Haar wavelet.
+	Haar Wavelet = iota
+	// D4 is the 4 tap Daubechies wavelet with 2 vanishing moments.
+	D4
+)
+
+func (w Wavelet) String() string {
+	switch w {
+	case Haar:
+		return "Haar"
+	case D4:
+		return "D4"
+	}
+	return "Unknown"
+}
+
+var (
+	ErrInvalidInput = errors.New("wavelet: invalid input")
+	ErrInvalidSize  = errors.New("wavelet: size is not divisible by 2^levels")
+)
+
+// Daubechies-4 low pass filter; the high pass one is g = (h3, -h2, h1, -h0).
+var (
+	d4_h0 = (1 + math.Sqrt(3)) / (4 * math.Sqrt2)
+	d4_h1 = (3 + math.Sqrt(3)) / (4 * math.Sqrt2)
+	d4_h2 = (3 - math.Sqrt(3)) / (4 * math.Sqrt2)
+	d4_h3 = (1 - math.Sqrt(3)) / (4 * math.Sqrt2)
+)
+
+// Forward1D replaces data with its levels level DWT. len(data) must be
+// divisible by 2^levels.
+func Forward1D(data []float64, levels int, w Wavelet) error {
+	if err := check(len(data), levels, w); err != nil {
+		return err
+	}
+
+	temp := make([]float64, len(data))
+	for l := 0; l < levels; l++ {
+		forward_step(data[:len(data)>>l], temp, w)
+	}
+	return nil
+}
+
+// Inverse1D is the inverse of Forward1D.
+func Inverse1D(data []float64, levels int, w Wavelet) error {
+	if err := check(len(data), levels, w); err != nil {
+		return err
+	}
+
+	temp := make([]float64, len(data))
+	for l := levels - 1; l >= 0; l-- {
+		inverse_step(data[:len(data)>>l], temp, w)
+	}
+	return nil
+}
+
+// Forward2D replaces the flattened rows x cols data with its levels level 2D
+// DWT, rows then columns at each level. rows and cols must be divisible by
+// 2^levels.
+func Forward2D(data []float64, rows, cols, levels int, w Wavelet) error {
+	if len(data) != rows*cols {
+		return ErrInvalidInput
+	}
+	if err := check(rows, levels, w); err != nil {
+		return err
+	}
+	if err := check(cols, levels, w); err != nil {
+		return err
+	}
+
+	temp := make([]float64, max(rows, cols))
+	col := make([]float64, rows)
+	for l := 0; l < levels; l++ {
+		r, c := rows>>l, cols>>l
+		for y := 0; y < r; y++ {
+			forward_step(data[y*cols:y*cols+c], temp, w)
+		}
+		for x := 0; x < c; x++ {
+			for y := 0; y < r; y++ {
+				col[y] = data[y*cols+x]
+			}
+			forward_step(col[:r], temp, w)
+			for y := 0; y < r; y++ {
+				data[y*cols+x] = col[y]
+			}
+		}
+	}
+	return nil
+}
+
+// Inverse2D is the inverse of Forward2D.
+func Inverse2D(data []float64, rows, cols, levels int, w Wavelet) error {
+	if len(data) != rows*cols {
+		return ErrInvalidInput
+	}
+	if err := check(rows, levels, w); err != nil {
+		return err
+	}
+	if err := check(cols, levels, w); err != nil {
+		return err
+	}
+
+	temp := make([]float64, max(rows, cols))
+	col := make([]float64, rows)
+	for l := levels - 1; l >= 0; l-- {
+		r, c := rows>>l, cols>>l
+		for x := 0; x < c; x++ {
+			for y := 0; y < r; y++ {
+				col[y] = data[y*cols+x]
+			}
+			inverse_step(col[:r], temp, w)
+			for y := 0; y < r; y++ {
+				data[y*cols+x] = col[y]
+			}
+		}
+		for y := 0; y < r; y++ {
+			inverse_step(data[y*cols:y*cols+c], temp, w)
+		}
+	}
+	return nil
+}
+
+// check validates the transform of levels levels of a length n axis.
+func check(n, levels int, w Wavelet) error {
+	if n <= 0 || levels < 0 || (w != Haar && w != D4) {
+		return ErrInvalidInput
+	}
+	if levels >= 63 || n%(1<<levels) != 0 {
+		return ErrInvalidSize
+	}
+	return nil
+}
+
+// forward_step splits data, of even length, into its approximation and
+// detail halves.
+func forward_step(data, temp []float64, w Wavelet) {
+	n := len(data)
+	half := n / 2
+
+	switch w {
+	case Haar:
+		for i := 0; i < half; i++ {
+			a, b := data[2*i], data[2*i+1]
+			temp[i] = (a + b) / math.Sqrt2
+			temp[half+i] = (a - b) / math.Sqrt2
+		}
+	case D4:
+		for i := 0; i < half; i++ {
+			x0, x1 := data[2*i], data[2*i+1]
+			x2, x3 := data[(2*i+2)%n], data[(2*i+3)%n]
+			temp[i] = d4_h0*x0 + d4_h1*x1 + d4_h2*x2 + d4_h3*x3
+			temp[half+i] = d4_h3*x0 - d4_h2*x1 + d4_h1*x2 - d4_h0*x3
+		}
+	}
+	copy(data, temp[:n])
+}
+
+// inverse_step is the inverse of forward_step, the transpose of its
+// orthogonal matrix.
+func inverse_step(data, temp []float64, w Wavelet) {
+	n := len(data)
+	half := n / 2
+
+	switch w {
+	case Haar:
+		for i := 0; i < half; i++ {
+			s, d := data[i], data[half+i]
+			temp[2*i] = (s + d) / math.Sqrt2
+			temp[2*i+1] = (s - d) / math.Sqrt2
+		}
+	case D4:
+		for i := 0; i < half; i++ {
+			p := (i + half - 1) % half // the previous pair, wrapping around
+			s, d := data[i], data[half+i]
+			sp, dp := data[p], data[half+p]
+			temp[2*i] = d4_h0*s + d4_h3*d + d4_h2*sp + d4_h1*dp
+			temp[2*i+1] = d4_h1*s - d4_h2*d + d4_h3*sp - d4_h0*dp
+		}
+	}
+	copy(data, temp[:n])
+}
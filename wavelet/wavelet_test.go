@@ -0,0 +1,162 @@
+package wavelet
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+const (
+	EPSILON float64 = 0.00000001
+)
+
+func near(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > EPSILON {
+			return false
+		}
+	}
+	return true
+}
+
+func random(n int) []float64 {
+	r := rand.New(rand.NewSource(int64(n)))
+	data := make([]float64, n)
+	for i := range data {
+		data[i] = r.Float64()
+	}
+	return data
+}
+
+func TestHaar(t *testing.T) {
+	r := 1 / math.Sqrt2
+	for _, tt := range []struct {
+		input  []float64
+		rows   int // 0 for 1D
+		levels int
+		output []float64
+	}{
+		// pywt.wavedec([1, 2, 3, 4], "haar", level=1) and level=2
+		{[]float64{1, 2, 3, 4}, 0, 1, []float64{3 * r, 7 * r, -r, -r}},
+		{[]float64{1, 2, 3, 4}, 0, 2, []float64{5, -2, -r, -r}},
+		// pywt.dwt2([[1, 2], [3, 4]], "haar"), LL and the details along
+		// the rows, the columns and both
+		{[]float64{1, 2, 3, 4}, 2, 1, []float64{5, -1, -2, 0}},
+		{[]float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 4, 2, []float64{4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+	} {
+		out := slices.Clone(tt.input)
+		var err error
+		if tt.rows == 0 {
+			err = Forward1D(out, tt.levels, Haar)
+		} else {
+			err = Forward2D(out, tt.rows, len(out)/tt.rows, tt.levels, Haar)
+		}
+		if err != nil {
+			t.Fatalf("Haar(%v, %d) returned error %v", tt.input, tt.levels, err)
+		}
+		if !near(out, tt.output) {
+			t.Errorf("Haar(%v, %d) expected %v but got %v.", tt.input, tt.levels, tt.output, out)
+		}
+	}
+}
+
+func TestD4(t *testing.T) {
+	// two vanishing moments: the details of a linear ramp are zero away
+	// from the wrap around at the end
+	ramp := make([]float64, 16)
+	for i := range ramp {
+		ramp[i] = 3 + 0.5*float64(i)
+	}
+	if err := Forward1D(ramp, 1, D4); err != nil {
+		t.Fatalf("Forward1D returned error %v", err)
+	}
+	for i := 8; i < 15; i++ {
+		if math.Abs(ramp[i]) > EPSILON {
+			t.Errorf("D4 detail %d of a ramp is %v, expected 0.", i-8, ramp[i])
+		}
+	}
+
+	// the low pass filter sums to sqrt 2, so a constant scales by it
+	constant := []float64{1, 1, 1, 1, 1, 1, 1, 1}
+	if err := Forward1D(constant, 3, D4); err != nil {
+		t.Fatalf("Forward1D returned error %v", err)
+	}
+	if expect := []float64{2 * math.Sqrt2, 0, 0, 0, 0, 0, 0, 0}; !near(constant, expect) {
+		t.Errorf("D4 of a constant expected %v but got %v.", expect, constant)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, w := range []Wavelet{Haar, D4} {
+		for _, tt := range []struct {
+			rows, cols, levels int
+		}{
+			{1, 2, 1}, {1, 64, 6}, {1, 96, 5}, {8, 8, 3}, {32, 32, 2}, {16, 48, 4}, {64, 32, 0},
+		} {
+			input := random(tt.rows * tt.cols)
+			data := slices.Clone(input)
+
+			var err error
+			if tt.rows == 1 {
+				err = Forward1D(data, tt.levels, w)
+			} else {
+				err = Forward2D(data, tt.rows, tt.cols, tt.levels, w)
+			}
+			if err != nil {
+				t.Fatalf("%v forward %dx%d returned error %v", w, tt.rows, tt.cols, err)
+			}
+
+			// orthonormal: the energy is kept
+			var e_in, e_out float64
+			for i := range input {
+				e_in += input[i] * input[i]
+				e_out += data[i] * data[i]
+			}
+			if math.Abs(e_in-e_out) > EPSILON*e_in {
+				t.Errorf("%v %dx%d energy is %v, expected %v.", w, tt.rows, tt.cols, e_out, e_in)
+			}
+
+			if tt.rows == 1 {
+				err = Inverse1D(data, tt.levels, w)
+			} else {
+				err = Inverse2D(data, tt.rows, tt.cols, tt.levels, w)
+			}
+			if err != nil {
+				t.Fatalf("%v inverse %dx%d returned error %v", w, tt.rows, tt.cols, err)
+			}
+			if !near(data, input) {
+				t.Errorf("%v %dx%d levels %d did not round trip.", w, tt.rows, tt.cols, tt.levels)
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		n, rows, cols, levels int
+		w                     Wavelet
+		err                   error
+	}{
+		{16, 4, 4, 3, Haar, ErrInvalidSize},
+		{24, 4, 6, 2, D4, ErrInvalidSize},
+		{16, 4, 4, -1, Haar, ErrInvalidInput},
+		{15, 4, 4, 1, Haar, ErrInvalidInput},
+		{16, 4, 4, 1, Wavelet(7), ErrInvalidInput},
+	} {
+		data := make([]float64, tt.n)
+		if err := Forward2D(data, tt.rows, tt.cols, tt.levels, tt.w); err != tt.err {
+			t.Errorf("Forward2D(%d, %dx%d, %d, %v) expected error %v but got %v.", tt.n, tt.rows, tt.cols, tt.levels, tt.w, tt.err, err)
+		}
+		if err := Inverse2D(data, tt.rows, tt.cols, tt.levels, tt.w); err != tt.err {
+			t.Errorf("Inverse2D(%d, %dx%d, %d, %v) expected error %v but got %v.", tt.n, tt.rows, tt.cols, tt.levels, tt.w, tt.err, err)
+		}
+	}
+
+	if err := Forward1D(make([]float64, 12), 3, Haar); err != ErrInvalidSize {
+		t.Errorf("Forward1D of 12 values, 3 levels expected error %v but got %v.", ErrInvalidSize, err)
+	}
+}